@@ -0,0 +1,41 @@
+package deadletter
+
+import (
+	"time"
+
+	"github.com/dukfaar/itemBackend/logging"
+	nsq "github.com/nsqio/go-nsq"
+)
+
+// NewConsumer subscribes handler to topic on channel with an nsq consumer of its own. The event bus only hands the
+// body of a message to its handlers, WithDeadLetter needs the attempts of the message and the requeue of nsq.
+// A requeued message is delivered again after requeueDelay times its attempts.
+func NewConsumer(topic string, channel string, lookupdAddress string, requeueDelay time.Duration, logger *logging.Logger, handler nsq.Handler) (*nsq.Consumer, error) {
+	config := nsq.NewConfig()
+	// WithDeadLetter decides when to give up on a message
+	config.MaxAttempts = 0
+	config.DefaultRequeueDelay = requeueDelay
+
+	consumer, err := nsq.NewConsumer(topic, channel, config)
+	if err != nil {
+		return nil, err
+	}
+	consumer.SetLogger(nsqLogger{logger.With(logging.FieldTopic, topic)}, nsq.LogLevelWarning)
+	consumer.AddHandler(handler)
+
+	if err := consumer.ConnectToNSQLookupd(lookupdAddress); err != nil {
+		consumer.Stop()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+// nsqLogger writes the log lines of the nsq client, they are warnings and errors only
+type nsqLogger struct {
+	logger *logging.Logger
+}
+
+func (l nsqLogger) Output(calldepth int, s string) error {
+	l.logger.Warn(s)
+	return nil
+}
@@ -0,0 +1,60 @@
+package deadletter
+
+import (
+	"encoding/json"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	"github.com/dukfaar/itemBackend/logging"
+	nsq "github.com/nsqio/go-nsq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var parked = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "itembackend_dead_letters_total",
+	Help: "Events parked in the dead letter store after failing every attempt",
+}, []string{"topic"})
+
+func init() {
+	prometheus.MustRegister(parked)
+}
+
+// WithDeadLetter hands a failing event back to nsq, which delivers it again after the requeue delay and backoff of
+// the consumer. Once maxAttempts deliveries have failed the event is parked in the dead letter store instead,
+// so a permanently broken event does not get requeued forever.
+func WithDeadLetter(service Service, logger *logging.Logger, topic string, maxAttempts int, handler func(msg []byte) error) nsq.Handler {
+	logger = logger.With(logging.FieldTopic, topic)
+
+	return nsq.HandlerFunc(func(message *nsq.Message) error {
+		err := handler(message.Body)
+		if err == nil {
+			return nil
+		}
+
+		attempts := int(message.Attempts)
+		if attempts < maxAttempts {
+			logger.Debug("Requeueing failed event", logging.FieldError, err, "attempts", attempts)
+			return err
+		}
+
+		// a dead letter that can not be stored is requeued and parked on its next delivery
+		model, storeErr := service.Create(topic, message.Body, err, int32(attempts))
+		if storeErr != nil {
+			logger.Error("Storing dead letter failed", logging.FieldError, storeErr, "handlerError", err)
+			return err
+		}
+		parked.WithLabelValues(topic).Inc()
+		logger.Warn("Parked event as dead letter", logging.FieldError, err, "deadLetterId", model.ID.Hex(), "attempts", attempts)
+
+		return nil
+	})
+}
+
+// Replay emits the stored payload on its topic again and removes the dead letter
+func Replay(service Service, bus eventbus.EventBus, model *Model) error {
+	err := bus.Emit(model.Topic, json.RawMessage(model.Payload))
+	if err != nil {
+		return err
+	}
+
+	return service.DeleteByID(model.ID.Hex())
+}
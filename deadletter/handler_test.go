@@ -0,0 +1,65 @@
+package deadletter
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/dukfaar/itemBackend/logging"
+	nsq "github.com/nsqio/go-nsq"
+)
+
+type memoryService struct {
+	Service
+	created []Model
+}
+
+func (s *memoryService) Create(topic string, payload []byte, err error, attempts int32) (*Model, error) {
+	model := Model{Topic: topic, Payload: string(payload), Error: err.Error(), Attempts: attempts}
+	s.created = append(s.created, model)
+	return &model, nil
+}
+
+func TestWithDeadLetter(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    uint16
+		handlerErr  error
+		wantErr     bool
+		wantParked  bool
+		wantAttempt int32
+	}{
+		{"handled", 1, nil, false, false, 0},
+		{"requeued", 1, errors.New("broken"), true, false, 0},
+		{"last attempt", 3, errors.New("broken"), false, true, 3},
+		{"beyond the last attempt", 4, errors.New("broken"), false, true, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &memoryService{}
+			calls := 0
+
+			handler := WithDeadLetter(service, logging.New(ioutil.Discard, logging.LevelError), "topic", 3, func(msg []byte) error {
+				calls++
+				return tt.handlerErr
+			})
+
+			message := nsq.NewMessage(nsq.MessageID{}, []byte(`{"name":"abc"}`))
+			message.Attempts = tt.attempts
+			err := handler.HandleMessage(message)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithDeadLetter() error = %v, want an error %v so nsq requeues the event", err, tt.wantErr)
+			}
+			if calls != 1 {
+				t.Errorf("WithDeadLetter() called the handler %v times, want once per delivery", calls)
+			}
+			if parked := len(service.created) == 1; parked != tt.wantParked {
+				t.Fatalf("WithDeadLetter() stored %+v, want parked %v", service.created, tt.wantParked)
+			}
+			if tt.wantParked && (service.created[0].Error != "broken" || service.created[0].Attempts != tt.wantAttempt) {
+				t.Errorf("WithDeadLetter() stored %+v, want %v attempts", service.created[0], tt.wantAttempt)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+type Model struct {
+	ID           bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Topic        string        `json:"topic" bson:"topic"`
+	Payload      string        `json:"payload" bson:"payload"`
+	Error        string        `json:"error" bson:"error"`
+	Attempts     int32         `json:"attempts" bson:"attempts"`
+	CreatedAt    time.Time     `json:"createdAt" bson:"createdAt"`
+	LastFailedAt time.Time     `json:"lastFailedAt" bson:"lastFailedAt"`
+}
+
+// NamespaceID reads the namespace the parked import event writes to, it is empty if the payload names none
+func (m *Model) NamespaceID() bson.ObjectId {
+	var payload struct {
+		Namespace string `json:"namespace"`
+	}
+	if json.Unmarshal([]byte(m.Payload), &payload) != nil || !bson.IsObjectIdHex(payload.Namespace) {
+		return ""
+	}
+	return bson.ObjectIdHex(payload.Namespace)
+}
+
+var GraphQLType = `
+	type DeadLetter {
+		_id: ID!
+		topic: String!
+		payload: String!
+		error: String!
+		attempts: Int!
+		createdAt: String!
+		lastFailedAt: String!
+	}
+`
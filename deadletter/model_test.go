@@ -0,0 +1,28 @@
+package deadletter
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestModel_NamespaceID(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bson.ObjectId
+	}{
+		{"namespace", `{"name":"Copper Ore","namespace":"10112233445566778899aabb"}`, bson.ObjectIdHex("10112233445566778899aabb")},
+		{"no namespace", `{"name":"Copper Ore"}`, ""},
+		{"invalid namespace", `{"namespace":"FFXIV"}`, ""},
+		{"no object", `"00112233445566778899aabb"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &Model{Payload: tt.payload}
+			if got := model.NamespaceID(); got != tt.want {
+				t.Errorf("Model.NamespaceID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package deadletter
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Topic() string {
+	return r.Model.Topic
+}
+
+func (r *Resolver) Payload() string {
+	return r.Model.Payload
+}
+
+func (r *Resolver) Error() string {
+	return r.Model.Error
+}
+
+func (r *Resolver) Attempts() int32 {
+	return r.Model.Attempts
+}
+
+func (r *Resolver) CreatedAt() string {
+	return r.Model.CreatedAt.Format(time.RFC3339)
+}
+
+func (r *Resolver) LastFailedAt() string {
+	return r.Model.LastFailedAt.Format(time.RFC3339)
+}
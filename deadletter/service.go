@@ -0,0 +1,82 @@
+package deadletter
+
+import (
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type Service interface {
+	Create(topic string, payload []byte, err error, attempts int32) (*Model, error)
+	FindByID(string) (*Model, error)
+	List(topic *string, first *int32, after *string) ([]Model, error)
+	ListByTopic(topic string) ([]Model, error)
+	DeleteByID(string) error
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("deadLetters"),
+	}
+}
+
+func (s *MgoService) Create(topic string, payload []byte, err error, attempts int32) (*Model, error) {
+	now := time.Now()
+	model := &Model{
+		ID:           bson.NewObjectId(),
+		Topic:        topic,
+		Payload:      string(payload),
+		Error:        err.Error(),
+		Attempts:     attempts,
+		CreatedAt:    now,
+		LastFailedAt: now,
+	}
+
+	insertErr := s.collection.Insert(model)
+
+	return model, insertErr
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) List(topic *string, first *int32, after *string) ([]Model, error) {
+	query := bson.M{}
+	if topic != nil {
+		query["topic"] = *topic
+	}
+	if after != nil {
+		query["_id"] = bson.M{"$gt": bson.ObjectIdHex(*after)}
+	}
+
+	limit := 100
+	if first != nil {
+		limit = int(*first)
+	}
+
+	var result []Model
+	err := s.collection.Find(query).Sort("_id").Limit(limit).All(&result)
+	return result, err
+}
+
+func (s *MgoService) ListByTopic(topic string) ([]Model, error) {
+	var result []Model
+	err := s.collection.Find(bson.M{"topic": topic}).Sort("_id").All(&result)
+	return result, err
+}
+
+func (s *MgoService) DeleteByID(id string) error {
+	return s.collection.RemoveId(bson.ObjectIdHex(id))
+}
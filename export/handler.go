@@ -0,0 +1,159 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Query builds the item query of the filter, limited to the namespaces in which the caller holds both
+// the export permission name and the permission to read items. A namespace of the filter the caller
+// can not export from is rejected instead of being left out.
+func (f Filter) Query(ctx context.Context, itemService item.Service, name string) (bson.M, error) {
+	query := itemService.MakeBaseQuery()
+	if f.Name != nil {
+		itemService.MakeNameRegexQuery(query, *f.Name, "i")
+	}
+
+	if f.NamespaceID != nil {
+		if !bson.IsObjectIdHex(*f.NamespaceID) {
+			return nil, fmt.Errorf("Invalid namespace id: %v", *f.NamespaceID)
+		}
+		namespaceID := bson.ObjectIdHex(*f.NamespaceID)
+
+		for _, permissionName := range []string{name, "query.items"} {
+			if err := item.CheckInNamespace(ctx, permissionName, namespaceID); err != nil {
+				return nil, err
+			}
+		}
+		query["namespaceId"] = namespaceID
+		return query, nil
+	}
+
+	exportable, err := item.ReadableNamespaces(ctx, name, itemService.NamespaceIDs)
+	if err != nil {
+		return nil, err
+	}
+	readable, err := item.ReadableNamespaces(ctx, "query.items", itemService.NamespaceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespaceIDs := intersectNamespaces(exportable, readable); namespaceIDs != nil {
+		query["namespaceId"] = bson.M{"$in": namespaceIDs}
+	}
+	return query, nil
+}
+
+// intersectNamespaces intersects two results of item.ReadableNamespaces, where nil stands for every namespace
+func intersectNamespaces(a []bson.ObjectId, b []bson.ObjectId) []bson.ObjectId {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	inB := make(map[bson.ObjectId]bool, len(b))
+	for _, namespaceID := range b {
+		inB[namespaceID] = true
+	}
+
+	result := make([]bson.ObjectId, 0)
+	for _, namespaceID := range a {
+		if inB[namespaceID] {
+			result = append(result, namespaceID)
+		}
+	}
+	return result
+}
+
+// Handler streams the items of an export, either of a handle created by the exportItems mutation (?id=)
+// or directly from the format, name and namespaceId query parameters
+type Handler struct {
+	Service     Service
+	ItemService item.Service
+}
+
+func (h *Handler) exportFromRequest(r *http.Request) (*Model, int, error) {
+	parameters := r.URL.Query()
+
+	if id := parameters.Get("id"); id != "" {
+		if !bson.IsObjectIdHex(id) {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid export id")
+		}
+
+		model, err := h.Service.FindByID(id)
+		if err == ErrExpired {
+			return nil, http.StatusGone, err
+		}
+		if err != nil {
+			return nil, http.StatusNotFound, err
+		}
+		return model, http.StatusOK, nil
+	}
+
+	model := &Model{Format: parameters.Get("format")}
+	if model.Format == "" {
+		model.Format = FormatJSON
+	}
+	if err := ValidateFormat(model.Format); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if name := parameters.Get("name"); name != "" {
+		model.Filter.Name = &name
+	}
+	if namespaceID := parameters.Get("namespaceId"); namespaceID != "" {
+		if !bson.IsObjectIdHex(namespaceID) {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid namespace id")
+		}
+		model.Filter.NamespaceID = &namespaceID
+	}
+
+	return model, http.StatusOK, nil
+}
+
+// ServeHTTP checks the permissions of the downloading caller, a handle grants no access of its own.
+// Downloading needs the permission of the exportItems mutation, like creating a handle.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	model, status, err := h.exportFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	itemService := item.WithContext(r.Context(), h.ItemService)
+	query, err := model.Filter.Query(r.Context(), itemService, "mutation.exportItems")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	logger := logging.FromContext(r.Context()).With("exportId", model.ID.Hex(), "format", model.Format)
+
+	w.Header().Set("Content-Type", ContentType(model.Format))
+	w.Header().Set("Content-Disposition", "attachment; filename=\"items."+model.Format+"\"")
+
+	writer, err := NewWriter(w, model.Format)
+	if err != nil {
+		logger.Error("Starting item export failed", logging.FieldError, err)
+		return
+	}
+
+	mask := item.NewReadMask(r.Context())
+	err = itemService.Iterate(query, func(model *item.Model) error {
+		return writer.Write(mask.Apply(model))
+	})
+	if err != nil {
+		logger.Error("Exporting items failed", logging.FieldError, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		logger.Error("Finishing item export failed", logging.FieldError, err)
+	}
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+type Filter struct {
+	Name        *string `json:"name,omitempty" bson:"name,omitempty"`
+	NamespaceID *string `json:"namespaceId,omitempty" bson:"namespaceId,omitempty"`
+}
+
+// Model is a download handle: it keeps the format and filter of a requested export until it expires,
+// the items themselves are only read when the handle is downloaded
+type Model struct {
+	ID        bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Format    string        `json:"format" bson:"format"`
+	Filter    Filter        `json:"filter" bson:"filter"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time     `json:"expiresAt" bson:"expiresAt"`
+}
+
+func (m *Model) URL() string {
+	return "/export?id=" + m.ID.Hex()
+}
+
+var GraphQLType = `
+	type ItemExport {
+		_id: ID!
+		format: String!
+		url: String!
+		expiresAt: String!
+	}
+
+	input ItemExportFilter {
+		name: String
+		namespaceId: ID
+	}
+`
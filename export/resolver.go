@@ -0,0 +1,27 @@
+package export
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Format() string {
+	return r.Model.Format
+}
+
+func (r *Resolver) URL() string {
+	return r.Model.URL()
+}
+
+func (r *Resolver) ExpiresAt() string {
+	return r.Model.ExpiresAt.Format(time.RFC3339)
+}
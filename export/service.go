@@ -0,0 +1,78 @@
+package export
+
+import (
+	"errors"
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+const DefaultTTL = time.Hour
+
+var ErrExpired = errors.New("Export has expired")
+
+type Service interface {
+	Create(format string, filter Filter) (*Model, error)
+	FindByID(string) (*Model, error)
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+	ttl        time.Duration
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("itemExports"),
+		ttl:        DefaultTTL,
+	}
+}
+
+// EnsureIndexes lets mongo remove expired handles
+func (s *MgoService) EnsureIndexes() error {
+	return s.collection.EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+		Background:  true,
+	})
+}
+
+func (s *MgoService) Create(format string, filter Filter) (*Model, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	if filter.NamespaceID != nil && !bson.IsObjectIdHex(*filter.NamespaceID) {
+		return nil, errors.New("Invalid namespace id")
+	}
+
+	now := time.Now()
+	model := &Model{
+		ID:        bson.NewObjectId(),
+		Format:    format,
+		Filter:    filter,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+
+	err := s.collection.Insert(model)
+
+	return model, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(result.ExpiresAt) {
+		return nil, ErrExpired
+	}
+
+	return &result, nil
+}
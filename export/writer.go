@@ -0,0 +1,247 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Columns is the stable column order of CSV exports. The keys match those of the offline file import,
+// so an export can be read back with the json, jsonl or csv import format.
+var Columns = []string{
+	"_id",
+	"namespaceId",
+	"id",
+	"name_en",
+	"name_de",
+	"name_fr",
+	"name_ja",
+	"help_en",
+	"help_de",
+	"help_fr",
+	"help_ja",
+	"gatheringLevel",
+	"gatheringJobId",
+	"gatheringEffort",
+	"price",
+	"priceHq",
+	"unspoiledNode",
+	"unspoiledNodeTime.time",
+	"unspoiledNodeTime.duration",
+	"unspoiledNodeTime.ampm",
+	"unspoiledNodeTime.folkloreNeeded",
+	"availableFromNpc",
+}
+
+type Record struct {
+	ID                bson.ObjectId           `json:"_id"`
+	NamespaceID       bson.ObjectId           `json:"namespaceId"`
+	XivdbID           *int32                  `json:"id,omitempty"`
+	NameEN            string                  `json:"name_en,omitempty"`
+	NameDE            *string                 `json:"name_de,omitempty"`
+	NameFR            *string                 `json:"name_fr,omitempty"`
+	NameJA            *string                 `json:"name_ja,omitempty"`
+	HelpEN            *string                 `json:"help_en,omitempty"`
+	HelpDE            *string                 `json:"help_de,omitempty"`
+	HelpFR            *string                 `json:"help_fr,omitempty"`
+	HelpJA            *string                 `json:"help_ja,omitempty"`
+	GatheringLevel    *int32                  `json:"gatheringLevel,omitempty"`
+	GatheringJobID    *bson.ObjectId          `json:"gatheringJobId,omitempty"`
+	GatheringEffort   *int32                  `json:"gatheringEffort,omitempty"`
+	Price             *int32                  `json:"price,omitempty"`
+	PriceHQ           *int32                  `json:"priceHq,omitempty"`
+	UnspoiledNode     *bool                   `json:"unspoiledNode,omitempty"`
+	UnspoiledNodeTime *item.UnspoiledNodeTime `json:"unspoiledNodeTime,omitempty"`
+	AvailableFromNpc  *bool                   `json:"availableFromNpc,omitempty"`
+}
+
+func NewRecord(model *item.Model) *Record {
+	description := model.Descriptions.Get("en")
+	if model.Description != nil {
+		description = model.Description
+	}
+
+	return &Record{
+		ID:                model.ID,
+		NamespaceID:       model.NamespaceID,
+		XivdbID:           model.XivdbID,
+		NameEN:            model.Name,
+		NameDE:            model.Names.Get("de"),
+		NameFR:            model.Names.Get("fr"),
+		NameJA:            model.Names.Get("ja"),
+		HelpEN:            description,
+		HelpDE:            model.Descriptions.Get("de"),
+		HelpFR:            model.Descriptions.Get("fr"),
+		HelpJA:            model.Descriptions.Get("ja"),
+		GatheringLevel:    model.GatheringLevel,
+		GatheringJobID:    model.GatheringJobID,
+		GatheringEffort:   model.GatheringEffort,
+		Price:             model.Price,
+		PriceHQ:           model.PriceHQ,
+		UnspoiledNode:     model.UnspoiledNode,
+		UnspoiledNodeTime: model.UnspoiledNodeTime,
+		AvailableFromNpc:  model.AvailableFromNpc,
+	}
+}
+
+func formatString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func formatInt(value *int32) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*value), 10)
+}
+
+func formatBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func formatID(value *bson.ObjectId) string {
+	if value == nil || *value == "" {
+		return ""
+	}
+	return value.Hex()
+}
+
+// CSV returns the values of the record in the order of Columns
+func (r *Record) CSV() []string {
+	unspoiledNodeTime := r.UnspoiledNodeTime
+	if unspoiledNodeTime == nil {
+		unspoiledNodeTime = &item.UnspoiledNodeTime{}
+	}
+
+	return []string{
+		formatID(&r.ID),
+		formatID(&r.NamespaceID),
+		formatInt(r.XivdbID),
+		r.NameEN,
+		formatString(r.NameDE),
+		formatString(r.NameFR),
+		formatString(r.NameJA),
+		formatString(r.HelpEN),
+		formatString(r.HelpDE),
+		formatString(r.HelpFR),
+		formatString(r.HelpJA),
+		formatInt(r.GatheringLevel),
+		formatID(r.GatheringJobID),
+		formatInt(r.GatheringEffort),
+		formatInt(r.Price),
+		formatInt(r.PriceHQ),
+		formatBool(r.UnspoiledNode),
+		formatInt(unspoiledNodeTime.Time),
+		formatInt(unspoiledNodeTime.Duration),
+		formatString(unspoiledNodeTime.AmPm),
+		formatString(unspoiledNodeTime.FolkloreNeeded),
+		formatBool(r.AvailableFromNpc),
+	}
+}
+
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatJSON, FormatNDJSON, FormatCSV:
+		return nil
+	}
+	return fmt.Errorf("Unknown export format: %v", format)
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// Writer writes the items of an export one by one, Close finishes the document.
+// Fields the caller may not read have to be cleared with item.ReadMask before.
+type Writer interface {
+	Write(*item.Model) error
+	Close() error
+}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{writer: w, first: true}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		csvWriter := csv.NewWriter(w)
+		return &csvExportWriter{writer: csvWriter}, csvWriter.Write(Columns)
+	}
+	return nil, ValidateFormat(format)
+}
+
+type jsonWriter struct {
+	writer io.Writer
+	first  bool
+}
+
+func (w *jsonWriter) Write(model *item.Model) error {
+	separator := ","
+	if w.first {
+		separator = "["
+		w.first = false
+	}
+
+	data, err := json.Marshal(NewRecord(model))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w.writer, separator); err != nil {
+		return err
+	}
+	_, err = w.writer.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	if w.first {
+		_, err := io.WriteString(w.writer, "[]")
+		return err
+	}
+	_, err := io.WriteString(w.writer, "]")
+	return err
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(model *item.Model) error {
+	return w.encoder.Encode(NewRecord(model))
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvExportWriter) Write(model *item.Model) error {
+	return w.writer.Write(NewRecord(model).CSV())
+}
+
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
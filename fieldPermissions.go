@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dukfaar/itemBackend/graphqlws"
+	"github.com/dukfaar/itemBackend/item"
+)
+
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// MaskDeniedFields resolves item fields the caller may not read to null and adds one partial error per denied
+// permission to the response, instead of failing the field of every single item
+func MaskDeniedFields(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, masked := item.WithMaskedFields(r.Context())
+		buffered := &bufferedResponseWriter{header: w.Header(), status: http.StatusOK}
+
+		next.ServeHTTP(buffered, r.WithContext(ctx))
+
+		body := buffered.body.Bytes()
+		if permissions := masked.Permissions(); len(permissions) > 0 {
+			var response map[string]interface{}
+			if err := json.Unmarshal(body, &response); err == nil {
+				errors, _ := response["errors"].([]interface{})
+				response["errors"] = appendPermissionErrors(errors, permissions)
+
+				if encoded, err := json.Marshal(response); err == nil {
+					body = encoded
+				}
+			}
+		}
+
+		w.WriteHeader(buffered.status)
+		w.Write(body)
+	})
+}
+
+// appendPermissionErrors adds one partial error per masked permission to the errors of a graphql response
+func appendPermissionErrors(errors []interface{}, permissions []string) []interface{} {
+	for _, name := range permissions {
+		errors = append(errors, map[string]interface{}{
+			"message": "No permission: " + name,
+			"extensions": map[string]interface{}{
+				"code":       "FORBIDDEN",
+				"permission": name,
+			},
+		})
+	}
+	return errors
+}
+
+// MaskDeniedSocketFields masks denied item fields like MaskDeniedFields for the operations of a graphql socket.
+// The operations of a socket share its context, so the partial errors of a field are added to the next result sent
+// after it has been masked, which may be the result of another operation running side by side.
+func MaskDeniedSocketFields(next *graphqlws.Handler) http.Handler {
+	next.Results = append(next.Results, addMaskedFieldErrors)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := item.WithMaskedFields(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// addMaskedFieldErrors adds the permissions masked since the last result of the socket to a result as partial errors
+func addMaskedFieldErrors(r *http.Request, payload map[string]json.RawMessage) error {
+	masked := item.MaskedFieldsFromContext(r.Context())
+	if masked == nil {
+		return nil
+	}
+
+	return addPermissionErrors(payload, masked.Take())
+}
+
+// addPermissionErrors adds the partial errors of permissions to the errors of a result payload
+func addPermissionErrors(payload map[string]json.RawMessage, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	var errors []interface{}
+	if raw, ok := payload["errors"]; ok {
+		if err := json.Unmarshal(raw, &errors); err != nil {
+			return err
+		}
+	}
+
+	encoded, err := json.Marshal(appendPermissionErrors(errors, permissions))
+	if err != nil {
+		return err
+	}
+	payload["errors"] = encoded
+	return nil
+}
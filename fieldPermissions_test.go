@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dukfaar/itemBackend/item"
+)
+
+func TestAddMaskedFieldErrors(t *testing.T) {
+	payload := map[string]json.RawMessage{"data": json.RawMessage(`{"item":{"name":null}}`)}
+
+	r := httptest.NewRequest("GET", "/socket", nil)
+	if err := addMaskedFieldErrors(r, payload); err != nil || len(payload) != 1 {
+		t.Errorf("addMaskedFieldErrors() without masked fields = %v, payload %s", err, payload)
+	}
+
+	ctx, _ := item.WithMaskedFields(r.Context())
+	if err := addMaskedFieldErrors(r.WithContext(ctx), payload); err != nil || len(payload) != 1 {
+		t.Errorf("addMaskedFieldErrors() before a field was masked = %v, payload %s", err, payload)
+	}
+}
+
+func TestAddPermissionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		errors  string
+		want    string
+		wantErr bool
+	}{
+		{"no errors", "", `[{"extensions":{"code":"FORBIDDEN","permission":"Item.name.read"},"message":"No permission: Item.name.read"}]`, false},
+		{"other errors", `[{"message":"Other error"}]`, `[{"message":"Other error"},{"extensions":{"code":"FORBIDDEN","permission":"Item.name.read"},"message":"No permission: Item.name.read"}]`, false},
+		{"errors not a list", `{}`, `{}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := map[string]json.RawMessage{"data": json.RawMessage(`{"item":{"name":null}}`)}
+			if tt.errors != "" {
+				payload["errors"] = json.RawMessage(tt.errors)
+			}
+
+			err := addPermissionErrors(payload, []string{"Item.name.read"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addPermissionErrors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := string(payload["errors"]); got != tt.want {
+				t.Errorf("addPermissionErrors() errors = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/tracing"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	FileImportFormatJSON         = "json"
+	FileImportFormatJSONL        = "jsonl"
+	FileImportFormatCSV          = "csv"
+	FileImportFormatSaintCoinach = "saintcoinach"
+)
+
+func FileImportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FileImportFormatJSONL
+	case ".csv":
+		return FileImportFormatCSV
+	}
+	return FileImportFormatJSON
+}
+
+// ReadItemDump parses a local data dump into the same event data the xivdb importer consumes
+func ReadItemDump(reader io.Reader, format string, locale string) ([]XivdbItemEventData, error) {
+	switch strings.ToLower(format) {
+	case FileImportFormatJSON, FileImportFormatJSONL:
+		return readJSONItemDump(reader)
+	case FileImportFormatCSV:
+		return readCSVItemDump(reader)
+	case FileImportFormatSaintCoinach:
+		return readSaintCoinachItemDump(reader, locale)
+	}
+
+	return nil, fmt.Errorf("Unknown import format: %v", format)
+}
+
+func readJSONItemDump(reader io.Reader) ([]XivdbItemEventData, error) {
+	bufferedReader := bufio.NewReader(reader)
+	decoder := json.NewDecoder(bufferedReader)
+	result := make([]XivdbItemEventData, 0)
+
+	firstByte, err := peekFirstNonSpace(bufferedReader)
+	if err == io.EOF {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if firstByte == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	for decoder.More() {
+		var data XivdbItemEventData
+		if err := decoder.Decode(&data); err != nil {
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func peekFirstNonSpace(reader *bufio.Reader) (byte, error) {
+	for offset := 1; ; offset++ {
+		peeked, err := reader.Peek(offset)
+		if len(peeked) < offset {
+			return 0, err
+		}
+
+		next := peeked[offset-1]
+		if next != ' ' && next != '\t' && next != '\r' && next != '\n' {
+			return next, nil
+		}
+	}
+}
+
+func readCSVItemDump(reader io.Reader) ([]XivdbItemEventData, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return []XivdbItemEventData{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return readCSVRecords(csvReader, header)
+}
+
+// readSaintCoinachItemDump reads an Item.csv as exported by SaintCoinach, which has a row of column indices,
+// a row of column names and a row of column types before the data
+func readSaintCoinachItemDump(reader io.Reader, locale string) ([]XivdbItemEventData, error) {
+	locale = item.NormalizeLocale(locale)
+	if locale == "" {
+		locale = item.DefaultLocale
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	var header []string
+	for row := 0; row < 3; row++ {
+		record, err := csvReader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading SaintCoinach header: %v", err)
+		}
+		if row == 1 {
+			header = record
+		}
+	}
+
+	for index, column := range header {
+		switch column {
+		case "#":
+			header[index] = "id"
+		case "Name":
+			header[index] = "name_" + locale
+		case "Description":
+			header[index] = "help_" + locale
+		default:
+			header[index] = ""
+		}
+	}
+
+	return readCSVRecords(csvReader, header)
+}
+
+var csvIntColumns = map[string]bool{
+	"id":                         true,
+	"gatheringLevel":             true,
+	"gatheringEffort":            true,
+	"price":                      true,
+	"priceHq":                    true,
+	"unspoiledNodeTime.time":     true,
+	"unspoiledNodeTime.duration": true,
+}
+
+var csvBoolColumns = map[string]bool{
+	"unspoiledNode":    true,
+	"availableFromNpc": true,
+}
+
+// parseCSVValue converts a cell to the type of its column. Empty typed cells yield nil, so the field stays unset.
+func parseCSVValue(column string, value string) (interface{}, error) {
+	trimmed := strings.TrimSpace(value)
+
+	switch {
+	case csvIntColumns[column]:
+		if trimmed == "" {
+			return nil, nil
+		}
+		number, err := strconv.ParseInt(trimmed, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %v %q: %v", column, value, err)
+		}
+		return number, nil
+	case csvBoolColumns[column]:
+		if trimmed == "" {
+			return nil, nil
+		}
+		flag, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %v %q: %v", column, value, err)
+		}
+		return flag, nil
+	}
+
+	return value, nil
+}
+
+// setCSVField sets a column like "unspoiledNodeTime.time" as a nested field
+func setCSVField(fields map[string]interface{}, column string, value interface{}) {
+	index := strings.Index(column, ".")
+	if index < 0 {
+		fields[column] = value
+		return
+	}
+
+	nested, ok := fields[column[:index]].(map[string]interface{})
+	if !ok {
+		nested = make(map[string]interface{})
+		fields[column[:index]] = nested
+	}
+	nested[column[index+1:]] = value
+}
+
+// dropEmptyNestedFields removes nested fields whose cells were all empty, e.g. the unspoiledNodeTime of a regular node
+func dropEmptyNestedFields(fields map[string]interface{}) {
+	for key, value := range fields {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		empty := true
+		for _, nestedValue := range nested {
+			empty = empty && nestedValue == ""
+		}
+		if empty {
+			delete(fields, key)
+		}
+	}
+}
+
+func readCSVRecords(csvReader *csv.Reader, header []string) ([]XivdbItemEventData, error) {
+	result := make([]XivdbItemEventData, 0)
+
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]interface{})
+		for index, value := range record {
+			if index >= len(header) || header[index] == "" {
+				continue
+			}
+
+			column := header[index]
+			parsed, err := parseCSVValue(column, value)
+			if err != nil {
+				return nil, err
+			}
+			if parsed != nil {
+				setCSVField(fields, column, parsed)
+			}
+		}
+		dropEmptyNestedFields(fields)
+
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+
+		var data XivdbItemEventData
+		if err := json.Unmarshal(encoded, &data); err != nil {
+			return nil, err
+		}
+		result = append(result, data)
+	}
+}
+
+func hasAnyName(data XivdbItemEventData) bool {
+	return data.NameEN != "" || data.NameDE != "" || data.NameFR != "" || data.NameJA != ""
+}
+
+// namedItems leaves out the items of a dump without any name, they can not be imported
+func namedItems(itemList []XivdbItemEventData) (named []XivdbItemEventData, skipped int) {
+	named = make([]XivdbItemEventData, 0, len(itemList))
+	for _, itemData := range itemList {
+		if hasAnyName(itemData) {
+			named = append(named, itemData)
+		}
+	}
+	return named, len(itemList) - len(named)
+}
+
+// EmitItemDump emits every item of itemList, which namedItems has filtered already
+func EmitItemDump(ctx context.Context, eventbus eventbus.EventBus, itemList []XivdbItemEventData, namespaceId string, reporting importReporting) {
+	logger := logging.FromContext(ctx)
+
+	for index := range itemList {
+		itemData := itemList[index]
+		itemData.NamespaceID = namespaceId
+		itemData.Source = item.SourceFile
+		itemData.importReporting = reporting.emitted()
+		err := tracing.Emit(ctx, eventbus, "import.item.by.xivdbid", itemData)
+
+		if err != nil {
+			logger.Error("Emitting import event failed", logging.FieldTopic, "import.item.by.xivdbid", "name", itemData.NameEN, logging.FieldError, err)
+		}
+	}
+}
+
+// itemDumpFields lists the item fields an import of itemList writes, so the caller's write permissions can be checked up front
+func itemDumpFields(itemList []XivdbItemEventData) []string {
+	mappedModels := make([]item.Model, len(itemList))
+	for index := range itemList {
+		itemData := itemList[index]
+		itemData.NamespaceID = bson.NewObjectId().Hex()
+		setModelFromXivdbEvent(&mappedModels[index], itemData)
+	}
+
+	return mappedFields(mappedModels)
+}
+
+func ReadItemDumpFile(path string, format string, locale string) ([]XivdbItemEventData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if format == "" {
+		format = FileImportFormatFromPath(path)
+	}
+
+	return ReadItemDump(file, format, locale)
+}
+
+func readItemDumpString(content string, format string, locale string) ([]XivdbItemEventData, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("Import content is empty")
+	}
+
+	return ReadItemDump(bytes.NewBufferString(content), format, locale)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/dukfaar/itemBackend/export"
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestReadItemDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		locale  string
+		content string
+		wantID  int32
+		wantEN  string
+		wantDE  string
+	}{
+		{
+			"json array",
+			FileImportFormatJSON,
+			"",
+			`[{"id": 5, "name_en": "Copper Ore", "name_de": "Kupfererz"}]`,
+			5, "Copper Ore", "Kupfererz",
+		},
+		{
+			"jsonl",
+			FileImportFormatJSONL,
+			"",
+			"{\"id\": 5, \"name_en\": \"Copper Ore\"}\n{\"id\": 6, \"name_en\": \"Tin Ore\"}\n",
+			5, "Copper Ore", "",
+		},
+		{
+			"csv",
+			FileImportFormatCSV,
+			"",
+			"id,name_en,name_de\n5,Copper Ore,Kupfererz\n",
+			5, "Copper Ore", "Kupfererz",
+		},
+		{
+			"saintcoinach",
+			FileImportFormatSaintCoinach,
+			"de",
+			"key,0,1\n#,Singular,Name\nint32,str,str\n5,kupfererz,Kupfererz\n",
+			5, "", "Kupfererz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadItemDump(bytes.NewBufferString(tt.content), tt.format, tt.locale)
+			if err != nil {
+				t.Fatalf("ReadItemDump() error = %v", err)
+			}
+			if len(got) == 0 {
+				t.Fatalf("ReadItemDump() returned no items")
+			}
+			if got[0].ID != tt.wantID || got[0].NameEN != tt.wantEN || got[0].NameDE != tt.wantDE {
+				t.Errorf("ReadItemDump() = %+v, want id %v, name_en %q, name_de %q", got[0], tt.wantID, tt.wantEN, tt.wantDE)
+			}
+		})
+	}
+}
+
+func newExportTestModel() *item.Model {
+	text := func(value string) *string { return &value }
+	number := func(value int32) *int32 { return &value }
+	flag := func(value bool) *bool { return &value }
+	gatheringJobID := bson.ObjectIdHex("20112233445566778899aabb")
+
+	return &item.Model{
+		ID:                bson.ObjectIdHex("00112233445566778899aabb"),
+		Name:              "Copper Ore",
+		Names:             &item.LocalizedText{En: text("Copper Ore"), De: text("Kupfererz")},
+		Description:       text("A chunk of ore."),
+		Descriptions:      &item.LocalizedText{En: text("A chunk of ore."), Fr: text("Un morceau, de minerai.")},
+		NamespaceID:       bson.ObjectIdHex("10112233445566778899aabb"),
+		XivdbID:           number(5),
+		GatheringLevel:    number(10),
+		GatheringJobID:    &gatheringJobID,
+		GatheringEffort:   number(2),
+		Price:             number(3),
+		PriceHQ:           number(4),
+		UnspoiledNode:     flag(true),
+		UnspoiledNodeTime: &item.UnspoiledNodeTime{Time: number(8), Duration: number(2), AmPm: text("AM"), FolkloreNeeded: text("")},
+		AvailableFromNpc:  flag(false),
+	}
+}
+
+func TestReadItemDump_ExportRoundTrip(t *testing.T) {
+	formats := map[string]string{
+		export.FormatJSON:   FileImportFormatJSON,
+		export.FormatNDJSON: FileImportFormatJSONL,
+		export.FormatCSV:    FileImportFormatCSV,
+	}
+
+	for exportFormat, importFormat := range formats {
+		t.Run(exportFormat, func(t *testing.T) {
+			original := newExportTestModel()
+
+			var buffer bytes.Buffer
+			writer, err := export.NewWriter(&buffer, exportFormat)
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			if err := writer.Write(original); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got, err := ReadItemDump(&buffer, importFormat, "")
+			if err != nil {
+				t.Fatalf("ReadItemDump() error = %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("ReadItemDump() read %v items, want 1", len(got))
+			}
+
+			got[0].NamespaceID = original.NamespaceID.Hex()
+			restored := &item.Model{}
+			setModelFromXivdbEvent(restored, got[0])
+
+			if diffs := item.Diff(original, restored); len(diffs) > 0 {
+				t.Errorf("round trip changed %v", diffs)
+			}
+		})
+	}
+}
+
+func TestItemDumpFields(t *testing.T) {
+	itemList, err := ReadItemDump(bytes.NewBufferString("id,name_en,name_de,price\n5,Copper Ore,Kupfererz,12\n"), FileImportFormatCSV, "")
+	if err != nil {
+		t.Fatalf("ReadItemDump() error = %v", err)
+	}
+
+	got := itemDumpFields(itemList)
+	want := []string{"name", "names", "namespaceId", "price", "xivdbId"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("itemDumpFields() = %v, want %v", got, want)
+	}
+}
+
+func TestNamedItems(t *testing.T) {
+	itemList := []XivdbItemEventData{
+		{ID: 5, NameEN: "Copper Ore", NameDE: "Kupfererz"},
+		{ID: 6},
+		{ID: 7, NameDE: "Eisenerz"},
+	}
+
+	named, skipped := namedItems(itemList)
+	if skipped != 1 {
+		t.Errorf("namedItems() skipped = %v, want 1", skipped)
+	}
+
+	gotIDs := make([]int32, len(named))
+	for index, itemData := range named {
+		gotIDs[index] = itemData.ID
+	}
+	if want := []int32{5, 7}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("namedItems() ids = %v, want %v", gotIDs, want)
+	}
+}
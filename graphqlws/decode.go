@@ -0,0 +1,53 @@
+package graphqlws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const CodeInvalidMessage = "INVALID_MESSAGE"
+
+// MessageFields are the fields of a socket message, RequestFields those of a graphql request, sent as the body of
+// a POST request or as the payload of a socket operation
+var (
+	MessageFields = []string{"id", "type", "payload"}
+	RequestFields = []string{"query", "operationName", "variables", "extensions"}
+)
+
+// MessageError is a message or request that can not be decoded, it is answered instead of passed on
+type MessageError struct {
+	Message string
+}
+
+func (e *MessageError) Error() string {
+	return e.Message
+}
+
+func (e *MessageError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": CodeInvalidMessage}
+}
+
+// DecodeObject decodes a JSON object into its raw fields. The graphql handlers decode into structs, which take the
+// last of the fields matching a name in any case and ignore data after the object. Anything they could read
+// differently from the returned fields, like trailing data or one of fields spelled in another case, is an error,
+// so a check on the fields can not be passed by a request the handler runs differently.
+func DecodeObject(data []byte, fields []string) (map[string]json.RawMessage, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, &MessageError{Message: "Malformed JSON: " + err.Error()}
+	}
+	if result == nil {
+		return nil, &MessageError{Message: "Expected a JSON object"}
+	}
+
+	for key := range result {
+		for _, field := range fields {
+			if key != field && strings.EqualFold(key, field) {
+				return nil, &MessageError{Message: fmt.Sprintf("Unknown field %q, did you mean %q", key, field)}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,366 @@
+package graphqlws
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/gorilla/websocket"
+)
+
+// the messages starting an operation and carrying its results, in subscriptions-transport-ws and in graphql-ws
+const (
+	typeStart     = "start"
+	typeSubscribe = "subscribe"
+	typeData      = "data"
+	typeNext      = "next"
+	typeError     = "error"
+)
+
+const handshakeTimeout = 10 * time.Second
+
+// Hook inspects the payload of every operation a client starts over a graphql socket before the socket handler
+// runs it, e.g. to fill in the query of a persisted query. An error answers the operation with an error message,
+// the socket stays open.
+type Hook func(r *http.Request, payload map[string]json.RawMessage) error
+
+// ResultHook inspects the payload of every result the socket handler sends to the client, e.g. to add errors.
+// An error is logged and the result is sent as it is.
+type ResultHook func(r *http.Request, payload map[string]json.RawMessage) error
+
+// Handler accepts graphql sockets itself and passes their messages through the hooks. The socket handler behind it
+// is served over an in-memory connection, so the hooks see whole, decoded messages of both socket protocols.
+type Handler struct {
+	Next     http.Handler
+	Upgrader websocket.Upgrader
+	Hooks    []Hook
+	Results  []ResultHook
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		h.Next.ServeHTTP(w, r)
+		return
+	}
+
+	logger := logging.FromContext(r.Context())
+
+	serverConn, clientConn := net.Pipe()
+	go h.serveNext(r, serverConn)
+
+	dialer := websocket.Dialer{
+		NetDial:          func(network, addr string) (net.Conn, error) { return clientConn, nil },
+		HandshakeTimeout: handshakeTimeout,
+		ReadBufferSize:   h.Upgrader.ReadBufferSize,
+		WriteBufferSize:  h.Upgrader.WriteBufferSize,
+	}
+	backend, response, err := dialer.Dial(nextURL(r), forwardedHeader(r.Header))
+	if err != nil {
+		clientConn.Close()
+		// the socket handler refused the upgrade, e.g. for the origin, the client gets the same answer
+		if response != nil {
+			copyResponse(w, response)
+			return
+		}
+		logger.Error("Connecting to the socket handler failed", logging.FieldError, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	responseHeader := http.Header{}
+	if protocol := backend.Subprotocol(); protocol != "" {
+		responseHeader.Set("Sec-Websocket-Protocol", protocol)
+	}
+	client, err := h.Upgrader.Upgrade(w, r, responseHeader)
+	if err != nil {
+		// the upgrader has answered the request already
+		backend.Close()
+		return
+	}
+
+	relay := &relay{request: r, logger: logger, hooks: h.Hooks, results: h.Results, client: client, backend: backend}
+	go relay.fromBackend()
+	relay.fromClient()
+}
+
+// serveNext reads the handshake the dialer sends over conn and serves it with the context of the original request
+func (h *Handler) serveNext(r *http.Request, conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	handshake, err := http.ReadRequest(reader)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	handshake = handshake.WithContext(r.Context())
+	handshake.RemoteAddr = r.RemoteAddr
+	handshake.URL = r.URL
+	handshake.RequestURI = r.RequestURI
+
+	w := &pipeResponseWriter{conn: conn, reader: reader, header: http.Header{}}
+	h.Next.ServeHTTP(w, handshake)
+	w.finish()
+}
+
+func nextURL(r *http.Request) string {
+	host := r.Host
+	if host == "" {
+		host = "localhost"
+	}
+	return (&url.URL{Scheme: "ws", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}).String()
+}
+
+// forwardedHeader copies the header of the client without the handshake fields the dialer sets itself
+func forwardedHeader(header http.Header) http.Header {
+	result := http.Header{}
+	for key, values := range header {
+		switch key {
+		case "Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions":
+			continue
+		}
+		result[key] = values
+	}
+	return result
+}
+
+func copyResponse(w http.ResponseWriter, response *http.Response) {
+	defer response.Body.Close()
+
+	for key, values := range response.Header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(response.StatusCode)
+	body, _ := ioutil.ReadAll(response.Body)
+	w.Write(body)
+}
+
+// pipeResponseWriter serves the socket handler over one end of an in-memory connection. A handler refusing the
+// upgrade instead of hijacking the connection gets its response written to the connection once it returns.
+type pipeResponseWriter struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (w *pipeResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *pipeResponseWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+func (w *pipeResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *pipeResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.conn, bufio.NewReadWriter(w.reader, bufio.NewWriter(w.conn)), nil
+}
+
+func (w *pipeResponseWriter) finish() {
+	if w.hijacked {
+		return
+	}
+	defer w.conn.Close()
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	response := &http.Response{
+		StatusCode:    w.status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          ioutil.NopCloser(&w.body),
+		ContentLength: int64(w.body.Len()),
+	}
+	response.Write(w.conn)
+}
+
+// relay passes messages between the client and the socket handler until either of them closes the socket
+type relay struct {
+	request *http.Request
+	logger  *logging.Logger
+	hooks   []Hook
+	results []ResultHook
+
+	client   *websocket.Conn
+	backend  *websocket.Conn
+	writeMux sync.Mutex
+}
+
+func (s *relay) writeClient(data []byte) error {
+	s.writeMux.Lock()
+	defer s.writeMux.Unlock()
+	return s.client.WriteMessage(websocket.TextMessage, data)
+}
+
+func (s *relay) fromBackend() {
+	defer s.client.Close()
+
+	for {
+		messageType, data, err := s.backend.ReadMessage()
+		if err != nil {
+			closeWith(s.client, err)
+			return
+		}
+
+		if messageType == websocket.TextMessage && len(s.results) > 0 {
+			data = s.handleResult(data)
+		}
+
+		s.writeMux.Lock()
+		err = s.client.WriteMessage(messageType, data)
+		s.writeMux.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *relay) fromClient() {
+	defer s.backend.Close()
+
+	for {
+		messageType, data, err := s.client.ReadMessage()
+		if err != nil {
+			closeWith(s.backend, err)
+			return
+		}
+
+		// the socket handler reads binary messages like text messages, they are checked alike
+		if messageType == websocket.TextMessage || messageType == websocket.BinaryMessage {
+			var reply []byte
+			if data, reply = s.handle(data); reply != nil {
+				if s.writeClient(reply) != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		if s.backend.WriteMessage(messageType, data) != nil {
+			return
+		}
+	}
+}
+
+// closeWith passes the close code the other side has sent on to conn
+func closeWith(conn *websocket.Conn, reason error) {
+	code, text := websocket.CloseNormalClosure, ""
+	if closeErr, ok := reason.(*websocket.CloseError); ok && closeErr.Code != websocket.CloseNoStatusReceived && closeErr.Code != websocket.CloseAbnormalClosure {
+		code, text = closeErr.Code, closeErr.Text
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+}
+
+// handle runs the hooks on a message starting an operation. It returns the message to pass on to the socket handler,
+// or the error message answering it. Messages that can not be decoded are answered, so the socket handler never runs
+// an operation the hooks have not seen.
+func (s *relay) handle(message []byte) ([]byte, []byte) {
+	fields, err := DecodeObject(message, MessageFields)
+	if err != nil {
+		return nil, s.errorMessage("", nil, err)
+	}
+
+	var messageType string
+	if raw, ok := fields["type"]; ok && json.Unmarshal(raw, &messageType) != nil {
+		return nil, s.errorMessage("", fields["id"], &MessageError{Message: "The type of a message must be a string"})
+	}
+	if messageType != typeStart && messageType != typeSubscribe {
+		return message, nil
+	}
+
+	payload, err := DecodeObject(fields["payload"], RequestFields)
+	if err != nil {
+		return nil, s.errorMessage(messageType, fields["id"], err)
+	}
+
+	for _, hook := range s.hooks {
+		if err := hook(s.request, payload); err != nil {
+			return nil, s.errorMessage(messageType, fields["id"], err)
+		}
+	}
+
+	if fields["payload"], err = json.Marshal(payload); err == nil {
+		message, err = json.Marshal(fields)
+	}
+	if err != nil {
+		return nil, s.errorMessage(messageType, fields["id"], err)
+	}
+	return message, nil
+}
+
+// handleResult runs the result hooks on a message carrying the result of an operation and returns the message to
+// send to the client. The socket handler is trusted, other messages and those it can not decode are sent as they are.
+func (s *relay) handleResult(message []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return message
+	}
+
+	var messageType string
+	if json.Unmarshal(fields["type"], &messageType) != nil || (messageType != typeData && messageType != typeNext) {
+		return message
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(fields["payload"], &payload); err != nil || payload == nil {
+		return message
+	}
+
+	for _, hook := range s.results {
+		if err := hook(s.request, payload); err != nil {
+			s.logger.Error("Checking socket result failed", logging.FieldError, err)
+			return message
+		}
+	}
+
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return message
+	}
+	fields["payload"] = encodedPayload
+
+	result, err := json.Marshal(fields)
+	if err != nil {
+		return message
+	}
+	return result
+}
+
+// errorMessage answers the operation id with err. Errors carrying extensions, like those of the persisted queries
+// and the query limits, are sent as they are, any other error is logged and reported as an internal error.
+func (s *relay) errorMessage(messageType string, id json.RawMessage, err error) []byte {
+	graphqlError := map[string]interface{}{"message": "Internal Server Error"}
+	if extended, ok := err.(interface {
+		Extensions() map[string]interface{}
+	}); ok {
+		graphqlError = map[string]interface{}{"message": err.Error(), "extensions": extended.Extensions()}
+	} else {
+		s.logger.Error("Checking socket operation failed", logging.FieldError, err)
+	}
+
+	// subscriptions-transport-ws sends a single error, graphql-ws a list like a graphql response
+	var payload interface{} = graphqlError
+	if messageType == typeSubscribe {
+		payload = []interface{}{graphqlError}
+	}
+
+	result, _ := json.Marshal(map[string]interface{}{"type": typeError, "id": id, "payload": payload})
+	return result
+}
@@ -0,0 +1,143 @@
+package graphqlws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// echoHandler stands in for the graphql socket handler and sends every message back
+func echoHandler(t *testing.T) http.Handler {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{"graphql-ws"},
+		CheckOrigin:  func(r *http.Request) bool { return r.Header.Get("Origin") != "https://evil.example" },
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			t.Errorf("socket handler got Authorization %q, want it passed on", r.Header.Get("Authorization"))
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if conn.WriteMessage(messageType, data) != nil {
+				return
+			}
+		}
+	})
+}
+
+type extendedError struct{}
+
+func (e *extendedError) Error() string {
+	return "PersistedQueryNotFound"
+}
+
+func (e *extendedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(&Handler{
+		Next:     echoHandler(t),
+		Upgrader: websocket.Upgrader{},
+		Hooks: []Hook{func(r *http.Request, payload map[string]json.RawMessage) error {
+			if _, ok := payload["query"]; !ok {
+				return &extendedError{}
+			}
+			payload["query"] = json.RawMessage(`"{ items { totalCount } }"`)
+			return nil
+		}},
+		Results: []ResultHook{func(r *http.Request, payload map[string]json.RawMessage) error {
+			if _, ok := payload["data"]; !ok {
+				return errors.New("Result without data")
+			}
+			payload["errors"] = json.RawMessage(`[{"message":"No permission: Item.name.read"}]`)
+			return nil
+		}},
+	})
+}
+
+func dial(t *testing.T, server *httptest.Server, header http.Header) (*websocket.Conn, *http.Response, error) {
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	return dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/socket", header)
+}
+
+func TestHandler_Messages(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	conn, _, err := dial(t, server, http.Header{"Authorization": {"Bearer abc"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	if conn.Subprotocol() != "graphql-ws" {
+		t.Errorf("Subprotocol() = %q, want the one of the socket handler", conn.Subprotocol())
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"other message", `{"type":"connection_init"}`, `{"type":"connection_init"}`},
+		{"malformed", `not json`, `{"id":null,"payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"Malformed JSON: invalid character 'o' in literal null (expecting 'u')"},"type":"error"}`},
+		{"trailing data", `{"id":"5","type":"start","payload":{}} x`, `{"id":null,"payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"Malformed JSON: invalid character 'x' after top-level value"},"type":"error"}`},
+		{"type in another case", `{"id":"6","Type":"start","payload":{}}`, `{"id":null,"payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"Unknown field \"Type\", did you mean \"type\""},"type":"error"}`},
+		{"type not a string", `{"id":"7","type":1}`, `{"id":"7","payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"The type of a message must be a string"},"type":"error"}`},
+		{"start without payload", `{"id":"8","type":"start"}`, `{"id":"8","payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"Malformed JSON: unexpected end of JSON input"},"type":"error"}`},
+		{"query in another case", `{"id":"9","type":"start","payload":{"query":"abc","Query":"{ secret }"}}`, `{"id":"9","payload":{"extensions":{"code":"INVALID_MESSAGE"},"message":"Unknown field \"Query\", did you mean \"query\""},"type":"error"}`},
+		{"start", `{"id":"1","type":"start","payload":{"query":"abc"}}`, `{"id":"1","payload":{"query":"{ items { totalCount } }"},"type":"start"}`},
+		{"start rejected", `{"id":"2","type":"start","payload":{}}`, `{"id":"2","payload":{"extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"},"message":"PersistedQueryNotFound"},"type":"error"}`},
+		{"subscribe rejected", `{"id":"3","type":"subscribe","payload":{}}`, `{"id":"3","payload":[{"extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"},"message":"PersistedQueryNotFound"}],"type":"error"}`},
+		{"open after rejection", `{"id":"4","type":"stop"}`, `{"id":"4","type":"stop"}`},
+		{"data result", `{"id":"1","type":"data","payload":{"data":{"item":null}}}`, `{"id":"1","payload":{"data":{"item":null},"errors":[{"message":"No permission: Item.name.read"}]},"type":"data"}`},
+		{"next result", `{"id":"3","type":"next","payload":{"data":{"item":null}}}`, `{"id":"3","payload":{"data":{"item":null},"errors":[{"message":"No permission: Item.name.read"}]},"type":"next"}`},
+		{"result hook failing", `{"id":"1","type":"data","payload":{"errors":[]}}`, `{"id":"1","type":"data","payload":{"errors":[]}}`},
+		{"result without payload", `{"id":"1","type":"complete"}`, `{"id":"1","type":"complete"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.message)); err != nil {
+				t.Fatalf("WriteMessage() error = %v", err)
+			}
+
+			_, got, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("ReadMessage() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadMessage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_RefusedUpgrade(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	_, response, err := dial(t, server, http.Header{"Authorization": {"Bearer abc"}, "Origin": {"https://evil.example"}})
+	if err == nil {
+		t.Fatalf("Dial() passed an origin the socket handler refuses")
+	}
+	if response == nil || response.StatusCode != http.StatusForbidden {
+		t.Errorf("Dial() response = %+v, want the refusal of the socket handler", response)
+	}
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+)
+
+// MongoPing pings the database through a copy of session, so a broken socket of session itself is not reused.
+// mgo takes no context, the ping gives up at the deadline of ctx through the timeouts of the copy instead.
+func MongoPing(session *mgo.Session) Check {
+	return func(ctx context.Context) error {
+		copied := session.Copy()
+		defer copied.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout := time.Until(deadline)
+			if timeout <= 0 {
+				return ctx.Err()
+			}
+			copied.SetSyncTimeout(timeout)
+			copied.SetSocketTimeout(timeout)
+		}
+
+		return copied.Ping()
+	}
+}
+
+// HTTPPing requests url and expects status 200, e.g. from the /ping endpoints of nsqd and nsqlookupd
+func HTTPPing(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.Do(request.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%v answered with status %v", url, response.StatusCode)
+		}
+		return nil
+	}
+}
+
+// GraphQLPing posts the cheapest possible query to a graphql endpoint. Any answer below status 500 means the endpoint
+// is up, the caller is not authenticated for it.
+func GraphQLPing(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"query":"{ __typename }"}`))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := client.Do(request.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+
+		if response.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%v answered with status %v", url, response.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Flag is a condition set once by the code that fulfils it, e.g. when the permission data has been loaded
+type Flag struct {
+	set int32
+}
+
+func (f *Flag) Set() {
+	atomic.StoreInt32(&f.set, 1)
+}
+
+func (f *Flag) IsSet() bool {
+	return atomic.LoadInt32(&f.set) == 1
+}
+
+// Check fails with message until the flag is set
+func (f *Flag) Check(message string) Check {
+	err := errors.New(message)
+	return func(ctx context.Context) error {
+		if !f.IsSet() {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusReady   = "ready"
+	StatusUnready = "unready"
+)
+
+var ErrShuttingDown = errors.New("Service is shutting down")
+
+// Check reports whether a dependency is usable, it should give up once ctx is done
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// CheckResult is the outcome of a single check in the body of a readiness response
+type CheckResult struct {
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the readiness checks of the service. Once shutdown has begun it reports unready without running them,
+// so the orchestrator stops sending requests while the running ones are drained.
+type Checker struct {
+	Timeout time.Duration
+
+	checks       []namedCheck
+	shuttingDown int32
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{Timeout: timeout}
+}
+
+// Add registers check under name. Checks have to be added before the checker serves requests.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+func (c *Checker) Shutdown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+func (c *Checker) ShuttingDown() bool {
+	return atomic.LoadInt32(&c.shuttingDown) == 1
+}
+
+// Run runs all checks at once, each of them is failed once the timeout has passed
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(c.checks)+1)}
+
+	if c.ShuttingDown() {
+		report.Status = StatusUnready
+		report.Checks["shutdown"] = CheckResult{Status: StatusFailed, Error: ErrShuttingDown.Error()}
+		return report
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var mutex sync.Mutex
+	var wait sync.WaitGroup
+	for _, check := range c.checks {
+		wait.Add(1)
+		go func(check namedCheck) {
+			defer wait.Done()
+
+			start := time.Now()
+			err := run(ctx, check.check)
+			result := CheckResult{Status: StatusOK, Duration: time.Since(start).Seconds()}
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Checks[check.name] = result
+			if err != nil {
+				report.Status = StatusUnready
+			}
+		}(check)
+	}
+	wait.Wait()
+
+	return report
+}
+
+// run gives up on check once ctx is done, even if check itself does not
+func run(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadyHandler answers with the report of every check, with status 503 unless all of them passed
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusReady {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// LiveHandler reports the process as alive as long as it serves requests, also while shutting down
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveReady(t *testing.T, checker *Checker) (int, Report) {
+	recorder := httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/readyz", nil))
+
+	var report Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("ReadyHandler() body %q is no report: %v", recorder.Body.String(), err)
+	}
+	return recorder.Code, report
+}
+
+func TestChecker_ReadyHandler(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	tests := []struct {
+		name       string
+		check      Check
+		wantCode   int
+		wantStatus string
+		wantError  string
+	}{
+		{"passing", func(ctx context.Context) error { return nil }, http.StatusOK, StatusOK, ""},
+		{"failing", func(ctx context.Context) error { return errors.New("connection refused") }, http.StatusServiceUnavailable, StatusFailed, "connection refused"},
+		{"hanging", func(ctx context.Context) error { <-blocked; return nil }, http.StatusServiceUnavailable, StatusFailed, context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(20 * time.Millisecond)
+			checker.Add("mongo", func(ctx context.Context) error { return nil })
+			checker.Add("dependency", tt.check)
+
+			code, report := serveReady(t, checker)
+
+			if code != tt.wantCode {
+				t.Errorf("ReadyHandler() status = %v, want %v", code, tt.wantCode)
+			}
+			if report.Checks["mongo"].Status != StatusOK {
+				t.Errorf("ReadyHandler() mongo = %+v, want it to pass on its own", report.Checks["mongo"])
+			}
+			if got := report.Checks["dependency"]; got.Status != tt.wantStatus || got.Error != tt.wantError {
+				t.Errorf("ReadyHandler() dependency = %+v, want status %v and error %q", got, tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestChecker_Shutdown(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("mongo", func(ctx context.Context) error { return nil })
+	checker.Shutdown()
+
+	code, report := serveReady(t, checker)
+
+	if code != http.StatusServiceUnavailable || report.Status != StatusUnready {
+		t.Errorf("ReadyHandler() = %v %v while shutting down, want unready", code, report.Status)
+	}
+	if report.Checks["shutdown"].Error != ErrShuttingDown.Error() {
+		t.Errorf("ReadyHandler() checks = %+v, want the shutdown reported", report.Checks)
+	}
+}
+
+func TestFlag_Check(t *testing.T) {
+	var flag Flag
+	check := flag.Check("Permission data has not been loaded")
+
+	if err := check(context.Background()); err == nil {
+		t.Errorf("Flag.Check() passed before the flag was set")
+	}
+	flag.Set()
+	if err := check(context.Background()); err != nil {
+		t.Errorf("Flag.Check() error = %v after the flag was set", err)
+	}
+}
+
+func TestGraphQLPing(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"answering", http.StatusOK, false},
+		{"unauthenticated", http.StatusUnauthorized, false},
+		{"failing", http.StatusBadGateway, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			if err := GraphQLPing(server.Client(), server.URL+"/graphql")(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("GraphQLPing() error = %v, want an error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGraphQLPing_GivesUpWithContext(t *testing.T) {
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-blocked:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- GraphQLPing(server.Client(), server.URL+"/graphql")(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("GraphQLPing() passed on a hanging endpoint")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("GraphQLPing() kept running after its context was done")
+	}
+}
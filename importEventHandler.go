@@ -1,68 +1,80 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"time"
 
-	dukgraphql "github.com/dukfaar/goUtils/graphql"
+	"github.com/dukfaar/itemBackend/importreport"
 	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/reference"
+	"github.com/dukfaar/itemBackend/review"
+	"github.com/dukfaar/itemBackend/tracing"
 	"github.com/globalsign/mgo/bson"
 )
 
 type RCItemEventData struct {
-	Name              string `json:"name" model:"Name"`
-	NamespaceID       string `json:"namespace"`
-	GatheringLevel    int32  `json:"gatheringLevel"`
-	GatheringJob      string `json:"gatheringJob"`
-	GatheringEffort   int32  `json:"gatheringEffort"`
-	Price             int32  `json:"price"`
-	PriceHQ           int32  `json:"priceHQ"`
-	UnspoiledNode     bool   `json:"unspoiledNode"`
-	UnspoiledNodeTime struct {
+	Name              string            `json:"name" model:"Name"`
+	Names             map[string]string `json:"names"`
+	Description       string            `json:"description"`
+	Descriptions      map[string]string `json:"descriptions"`
+	NamespaceID       string            `json:"namespace"`
+	Source            string            `json:"source,omitempty"`
+	GatheringLevel    *int32            `json:"gatheringLevel"`
+	GatheringJob      *string           `json:"gatheringJob"`
+	GatheringJobID    string            `json:"gatheringJobId,omitempty"`
+	GatheringEffort   *int32            `json:"gatheringEffort"`
+	Price             *int32            `json:"price"`
+	PriceHQ           *int32            `json:"priceHQ"`
+	UnspoiledNode     *bool             `json:"unspoiledNode"`
+	UnspoiledNodeTime *struct {
 		Time           int32  `json:"time"`
 		Duration       int32  `json:"duration"`
 		AmPm           string `json:"ampm"`
 		FolkloreNeeded string `json:"folkloreNeeded"`
 	} `json:"unspoiledNodeTime"`
-	AvailableFromNpc bool `json:"availableFromNpc"`
+	AvailableFromNpc *bool `json:"availableFromNpc"`
+	importReporting
 	//Add other vars here
 }
 
-var xivJobMap = make(map[string]string)
-
-func fetchFFXIVJob(fetcher dukgraphql.Fetcher, jobName string, namespace string) (string, error) {
-	if _, ok := xivJobMap[jobName]; ok {
-		return xivJobMap[jobName], nil
+func setModelFromRCEvent(itemModel *item.Model, data RCItemEventData, references *reference.Resolver) {
+	itemModel.Name = data.Name
+	if names := item.LocalizedTextFromMap(data.Names); names != nil {
+		itemModel.Names = names
 	}
-
-	fmt.Printf("fetching class %s in namespace %s\n", jobName, namespace)
-	classResult, err := fetcher.Fetch(dukgraphql.Request{
-		Query: "query { classByNameOrSynonym(name: \"" + jobName + "\", namespaceId: \"" + namespace + "\") { _id name } }",
-	})
-
-	if err != nil {
-		fmt.Printf("Error fetching job: %v\n", err)
-		return "", err
+	if data.Description != "" {
+		itemModel.Description = &data.Description
+	}
+	if descriptions := item.LocalizedTextFromMap(data.Descriptions); descriptions != nil {
+		itemModel.Descriptions = descriptions
 	}
-
-	classResponse := dukgraphql.Response{classResult}
-	fmt.Printf("result: %+v\n", classResponse)
-
-	result := classResponse.GetObject("classByNameOrSynonym").GetString("_id")
-	xivJobMap[jobName] = result
-	return result, nil
-}
-
-func setModelFromRCEvent(itemModel *item.Model, data RCItemEventData, fetcher dukgraphql.Fetcher) {
-	itemModel.Name = data.Name
 	itemModel.NamespaceID = bson.ObjectIdHex(data.NamespaceID)
-	itemModel.GatheringEffort = &data.GatheringEffort
+	if data.GatheringEffort != nil {
+		itemModel.GatheringEffort = data.GatheringEffort
+	}
 
-	gatheringJobChannel := make(chan *bson.ObjectId)
+	gatheringJobChannel := make(chan *bson.ObjectId, 1)
+	currentGatheringJobID := itemModel.GatheringJobID
 	go func() {
-		job, err := fetchFFXIVJob(fetcher, data.GatheringJob, data.NamespaceID)
+		if data.GatheringJobID != "" && bson.IsObjectIdHex(data.GatheringJobID) {
+			gatheringJobId := bson.ObjectIdHex(data.GatheringJobID)
+			gatheringJobChannel <- &gatheringJobId
+			return
+		}
+		if data.GatheringJob == nil {
+			gatheringJobChannel <- currentGatheringJobID
+			return
+		}
+
+		job, err := references.ClassID(*data.GatheringJob, data.NamespaceID)
 		if err != nil {
+			gatheringJobChannel <- currentGatheringJobID
+			return
+		}
+		if job == "" {
 			gatheringJobChannel <- nil
 			return
 		}
@@ -70,164 +82,396 @@ func setModelFromRCEvent(itemModel *item.Model, data RCItemEventData, fetcher du
 		gatheringJobChannel <- &gatheringJobId
 	}()
 
-	itemModel.GatheringLevel = &data.GatheringLevel
-	itemModel.Price = &data.Price
-	itemModel.PriceHQ = &data.PriceHQ
-	itemModel.UnspoiledNode = &data.UnspoiledNode
-	itemModel.UnspoiledNodeTime = &item.UnspoiledNodeTime{
-		Time:           &data.UnspoiledNodeTime.Time,
-		Duration:       &data.UnspoiledNodeTime.Duration,
-		AmPm:           &data.UnspoiledNodeTime.AmPm,
-		FolkloreNeeded: &data.UnspoiledNodeTime.FolkloreNeeded,
-	}
-	itemModel.AvailableFromNpc = &data.AvailableFromNpc
+	if data.GatheringLevel != nil {
+		itemModel.GatheringLevel = data.GatheringLevel
+	}
+	if data.Price != nil {
+		itemModel.Price = data.Price
+	}
+	if data.PriceHQ != nil {
+		itemModel.PriceHQ = data.PriceHQ
+	}
+	if data.UnspoiledNode != nil {
+		itemModel.UnspoiledNode = data.UnspoiledNode
+	}
+	if data.UnspoiledNodeTime != nil {
+		itemModel.UnspoiledNodeTime = &item.UnspoiledNodeTime{
+			Time:           &data.UnspoiledNodeTime.Time,
+			Duration:       &data.UnspoiledNodeTime.Duration,
+			AmPm:           &data.UnspoiledNodeTime.AmPm,
+			FolkloreNeeded: &data.UnspoiledNodeTime.FolkloreNeeded,
+		}
+	}
+	if data.AvailableFromNpc != nil {
+		itemModel.AvailableFromNpc = data.AvailableFromNpc
+	}
 	//Add other vars here
 
 	//add delayed fetches here
 	itemModel.GatheringJobID = <-gatheringJobChannel
 }
 
-func createItemModelFromRCEvent(itemService item.Service, itemData RCItemEventData, fetcher dukgraphql.Fetcher) error {
-	var itemModel = item.Model{}
-	setModelFromRCEvent(&itemModel, itemData, fetcher)
+// source is the provenance of the event, declarative import sources reuse the RC event with their own name
+func (data RCItemEventData) source() string {
+	if data.Source != "" {
+		return data.Source
+	}
+	return item.SourceRC
+}
+
+func createItemModelFromRCEvent(logger *logging.Logger, itemService item.Service, reportService importreport.Service, mergePolicy item.MergePolicy, itemData RCItemEventData, references *reference.Resolver) error {
+	var mappedModel = item.Model{}
+	setModelFromRCEvent(&mappedModel, itemData, references)
+	itemModel := mergePolicy.Merge(&item.Model{}, &mappedModel, itemData.source(), time.Now())
+
+	if itemData.DryRun {
+		return itemData.recordCreate(reportService, itemModel)
+	}
 
-	_, err := itemService.Create(&itemModel)
+	existingModel, created, err := itemService.CreateIfAbsent(bson.M{"namespaceId": itemModel.NamespaceID, "name": itemModel.Name}, itemModel)
 
 	if err != nil {
-		fmt.Printf("Error(%v) saving new item: %v\n", err, itemModel)
+		logger.Error("Saving new item failed", logging.FieldError, err, "name", itemModel.Name)
 		return err
 	}
 
-	return nil
+	if !created {
+		return updateItemModelFromRCEvent(logger, itemService, reportService, mergePolicy, existingModel, itemData, references)
+	}
+
+	logger.Debug("Created item", logging.FieldItemID, itemModel.ID.Hex())
+	return itemData.recordCreate(reportService, itemModel)
 }
 
-func updateItemModelFromRCEvent(itemService item.Service, itemModel *item.Model, itemData RCItemEventData, fetcher dukgraphql.Fetcher) error {
-	if itemModel == nil {
-		fmt.Println("itemModel is ni")
+func updateItemModelFromRCEvent(logger *logging.Logger, itemService item.Service, reportService importreport.Service, mergePolicy item.MergePolicy, before *item.Model, itemData RCItemEventData, references *reference.Resolver) error {
+	if before == nil {
+		logger.Error("Updating item failed, there is no item to update", "name", itemData.Name)
 		return errors.New("itemModel is nil")
 	}
+	logger = logger.With(logging.FieldItemID, before.ID.Hex())
 
-	setModelFromRCEvent(itemModel, itemData, fetcher)
+	mappedModel := before.Clone()
+	setModelFromRCEvent(mappedModel, itemData, references)
+	itemModel := mergePolicy.Merge(before, mappedModel, itemData.source(), time.Now())
+	diffs := item.Diff(before, itemModel)
+
+	if itemData.DryRun || len(diffs) == 0 {
+		return itemData.recordUpdate(reportService, before, itemModel, diffs)
+	}
 
 	_, err := itemService.Update(itemModel.ID.Hex(), itemModel)
 
 	if err != nil {
-		fmt.Printf("Error(%v) updating item: %v\n", err, itemModel)
+		logger.Error("Updating item failed", logging.FieldError, err)
 		return err
 	}
 
-	return nil
+	logger.Debug("Updated item", "fields", len(diffs))
+	return itemData.recordUpdate(reportService, before, itemModel, diffs)
 }
 
-func CreateRCEventImporter(itemService item.Service, fetcher dukgraphql.Fetcher) func(msg []byte) error {
-	return func(msg []byte) error {
+func CreateRCEventImporter(itemService item.Service, reportService importreport.Service, mergePolicy item.MergePolicy, references *reference.Resolver, logger *logging.Logger) func(msg []byte) error {
+	logger = logger.With(logging.FieldTopic, "import.item.by.rcname")
+
+	return recordDryRunRejections(reportService, logger, tracing.Consume("import.item.by.rcname", func(ctx context.Context, msg []byte) error {
 		var itemData RCItemEventData
 		err := json.Unmarshal(msg, &itemData)
 
 		if err != nil {
-			fmt.Printf("Error(%v) unmarshaling event data: %v\n", err, string(msg))
+			logger.Error("Unmarshaling event data failed", logging.FieldError, err, "payload", string(msg))
 			return err
 		}
 
+		logger := itemData.logger(logger)
+		itemService := item.WithContext(ctx, item.WithLogger(itemService, logger))
+		references := references.WithContext(ctx)
+
 		if itemData.Name == "" {
-			fmt.Printf("Cant import an item without a name\n")
+			logger.Warn("Can not import an item without a name")
 			return errors.New("Item has no Name")
 		}
+		if !bson.IsObjectIdHex(itemData.NamespaceID) {
+			logger.Warn("Can not import an item without a namespace", "name", itemData.Name)
+			return errors.New("Item has no valid namespace")
+		}
 
-		itemModel, err := itemService.FindByName(itemData.Name)
+		itemModel, err := itemService.FindByName(bson.ObjectIdHex(itemData.NamespaceID), itemData.Name)
 
 		if err != nil {
 			if err.Error() == "not found" {
-				return createItemModelFromRCEvent(itemService, itemData, fetcher)
+				return createItemModelFromRCEvent(logger, itemService, reportService, mergePolicy, itemData, references)
 			} else {
-				fmt.Printf("Unknown error: %v\n", err)
+				logger.Error("Finding item by name failed", logging.FieldError, err, "name", itemData.Name)
 				return err
 			}
 		}
 
-		return updateItemModelFromRCEvent(itemService, itemModel, itemData, fetcher)
-	}
+		return updateItemModelFromRCEvent(logger, itemService, reportService, mergePolicy, itemModel, itemData, references)
+	}))
 }
 
 type XivdbItemEventData struct {
 	ID          int32  `json:"id"`
 	NameEN      string `json:"name_en"`
+	NameDE      string `json:"name_de"`
+	NameFR      string `json:"name_fr"`
+	NameJA      string `json:"name_ja"`
+	HelpEN      string `json:"help_en"`
+	HelpDE      string `json:"help_de"`
+	HelpFR      string `json:"help_fr"`
+	HelpJA      string `json:"help_ja"`
 	NamespaceID string `json:"namespace"`
+	Source      string `json:"source,omitempty"`
+	importReporting
+
+	// the catalog fields below are only set by item dumps, e.g. a re-imported export
+	GatheringLevel    *int32                  `json:"gatheringLevel,omitempty"`
+	GatheringJobID    string                  `json:"gatheringJobId,omitempty"`
+	GatheringEffort   *int32                  `json:"gatheringEffort,omitempty"`
+	Price             *int32                  `json:"price,omitempty"`
+	PriceHQ           *int32                  `json:"priceHq,omitempty"`
+	UnspoiledNode     *bool                   `json:"unspoiledNode,omitempty"`
+	UnspoiledNodeTime *item.UnspoiledNodeTime `json:"unspoiledNodeTime,omitempty"`
+	AvailableFromNpc  *bool                   `json:"availableFromNpc,omitempty"`
 	//Add other vars here
 }
 
 func setModelFromXivdbEvent(itemModel *item.Model, data XivdbItemEventData) {
-	itemModel.Name = data.NameEN
+	if data.NameEN != "" {
+		itemModel.Name = data.NameEN
+	}
+	itemModel.Names = itemModel.Names.Merge(item.LocalizedTextFromMap(map[string]string{
+		"en": data.NameEN,
+		"de": data.NameDE,
+		"fr": data.NameFR,
+		"ja": data.NameJA,
+	}))
+	if data.HelpEN != "" {
+		itemModel.Description = &data.HelpEN
+	}
+	itemModel.Descriptions = itemModel.Descriptions.Merge(item.LocalizedTextFromMap(map[string]string{
+		"en": data.HelpEN,
+		"de": data.HelpDE,
+		"fr": data.HelpFR,
+		"ja": data.HelpJA,
+	}))
 	itemModel.NamespaceID = bson.ObjectIdHex(data.NamespaceID)
 
-	if itemModel.XivdbID == nil {
-		itemModel.XivdbID = new(int32)
+	if data.ID != 0 {
+		if itemModel.XivdbID == nil {
+			itemModel.XivdbID = new(int32)
+		}
+		*itemModel.XivdbID = data.ID
+	}
+
+	if data.GatheringLevel != nil {
+		itemModel.GatheringLevel = data.GatheringLevel
+	}
+	if bson.IsObjectIdHex(data.GatheringJobID) {
+		gatheringJobID := bson.ObjectIdHex(data.GatheringJobID)
+		itemModel.GatheringJobID = &gatheringJobID
+	}
+	if data.GatheringEffort != nil {
+		itemModel.GatheringEffort = data.GatheringEffort
+	}
+	if data.Price != nil {
+		itemModel.Price = data.Price
+	}
+	if data.PriceHQ != nil {
+		itemModel.PriceHQ = data.PriceHQ
+	}
+	if data.UnspoiledNode != nil {
+		itemModel.UnspoiledNode = data.UnspoiledNode
+	}
+	if data.UnspoiledNodeTime != nil {
+		itemModel.UnspoiledNodeTime = data.UnspoiledNodeTime
+	}
+	if data.AvailableFromNpc != nil {
+		itemModel.AvailableFromNpc = data.AvailableFromNpc
 	}
-	*itemModel.XivdbID = data.ID
 
 	//Add other vars here
 }
 
-func createItemModelFromXivdbEvent(itemService item.Service, itemData XivdbItemEventData) error {
-	var itemModel = item.Model{}
-	setModelFromXivdbEvent(&itemModel, itemData)
+// selector matches items by their xivdb id, dumps may also hold items without one which are matched by name
+func (data XivdbItemEventData) selector() bson.M {
+	if data.ID == 0 {
+		return bson.M{"namespaceId": bson.ObjectIdHex(data.NamespaceID), "name": data.NameEN}
+	}
+	return bson.M{"xivdbid": data.ID}
+}
+
+func (data XivdbItemEventData) source() string {
+	if data.Source != "" {
+		return data.Source
+	}
+	return item.SourceXivdb
+}
 
-	_, err := itemService.Create(&itemModel)
+func createItemModelFromXivdbEvent(logger *logging.Logger, itemService item.Service, reportService importreport.Service, mergePolicy item.MergePolicy, itemData XivdbItemEventData) error {
+	var mappedModel = item.Model{}
+	setModelFromXivdbEvent(&mappedModel, itemData)
+	itemModel := mergePolicy.Merge(&item.Model{}, &mappedModel, itemData.source(), time.Now())
+
+	if itemData.DryRun {
+		return itemData.recordCreate(reportService, itemModel)
+	}
+
+	existingModel, created, err := itemService.CreateIfAbsent(itemData.selector(), itemModel)
 
 	if err != nil {
-		fmt.Printf("Error(%v) creating item: %v\n", err, itemModel)
+		logger.Error("Creating item failed", logging.FieldError, err, "name", itemModel.Name)
 		return err
 	}
 
-	return nil
+	if !created {
+		return updateItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, existingModel, itemData)
+	}
+
+	logger.Debug("Created item", logging.FieldItemID, itemModel.ID.Hex())
+	return itemData.recordCreate(reportService, itemModel)
 }
 
-func updateItemModelFromXivdbEvent(itemService item.Service, itemModel *item.Model, itemData XivdbItemEventData) error {
-	if itemModel == nil {
-		fmt.Println("itemModel is ni")
+func updateItemModelFromXivdbEvent(logger *logging.Logger, itemService item.Service, reportService importreport.Service, mergePolicy item.MergePolicy, before *item.Model, itemData XivdbItemEventData) error {
+	if before == nil {
+		logger.Error("Updating item failed, there is no item to update", "name", itemData.NameEN)
 		return errors.New("itemModel is nil")
 	}
+	logger = logger.With(logging.FieldItemID, before.ID.Hex())
+
+	mappedModel := before.Clone()
+	setModelFromXivdbEvent(mappedModel, itemData)
+	itemModel := mergePolicy.Merge(before, mappedModel, itemData.source(), time.Now())
+	diffs := item.Diff(before, itemModel)
 
-	setModelFromXivdbEvent(itemModel, itemData)
+	if itemData.DryRun || len(diffs) == 0 {
+		return itemData.recordUpdate(reportService, before, itemModel, diffs)
+	}
 
 	_, err := itemService.Update(itemModel.ID.Hex(), itemModel)
 
 	if err != nil {
-		fmt.Printf("Error(%v) updating item: %v\n", err, itemModel)
+		logger.Error("Updating item failed", logging.FieldError, err)
 		return err
 	}
 
-	return nil
+	logger.Debug("Updated item", "fields", len(diffs))
+	return itemData.recordUpdate(reportService, before, itemModel, diffs)
+}
+
+// findXivdbCandidates lists the items of the event namespace that carry the english name of the event in any locale
+func findXivdbCandidates(itemService item.Service, itemData XivdbItemEventData) ([]item.Model, error) {
+	query := itemService.MakeBaseQuery()
+	itemService.MakeNameQuery(query, itemData.NameEN)
+	if bson.IsObjectIdHex(itemData.NamespaceID) {
+		query["namespaceId"] = bson.ObjectIdHex(itemData.NamespaceID)
+	}
+
+	return itemService.PerformListQuery(query, nil, nil, nil, nil)
 }
 
-func CreateXivdbEventImporter(itemService item.Service) func(msg []byte) error {
-	return func(msg []byte) error {
+// importUnlinkedXivdbItem imports an item whose xivdb id is not linked to any item yet.
+// A remembered curator decision is followed, otherwise name matches are parked for review instead of merged.
+func importUnlinkedXivdbItem(logger *logging.Logger, itemService item.Service, reportService importreport.Service, reviewService review.Service, mergePolicy item.MergePolicy, itemData XivdbItemEventData, msg []byte) error {
+	decision, err := reviewService.FindDecision(itemData.ID)
+	if err == nil {
+		if decision.Status == review.StatusRejected {
+			return nil
+		}
+
+		itemModel, err := itemService.FindByID(decision.ItemID.Hex())
+		if err == nil {
+			return updateItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, itemModel, itemData)
+		}
+		if err.Error() != "not found" {
+			return err
+		}
+	} else if err.Error() != "not found" {
+		return err
+	}
+
+	candidates, err := findXivdbCandidates(itemService, itemData)
+	if err != nil {
+		return err
+	}
+
+	reason := review.ReasonFor(candidates)
+	if reason == "" {
+		return createItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, itemData)
+	}
+
+	if !itemData.DryRun {
+		candidateIDs := make([]bson.ObjectId, len(candidates))
+		for i := range candidates {
+			candidateIDs[i] = candidates[i].ID
+		}
+
+		reviewModel := &review.Model{
+			Topic:      "import.item.by.xivdbid",
+			Source:     itemData.source(),
+			XivdbID:    itemData.ID,
+			Name:       itemData.NameEN,
+			Payload:    string(msg),
+			Reason:     reason,
+			Candidates: candidateIDs,
+		}
+		if bson.IsObjectIdHex(itemData.NamespaceID) {
+			reviewModel.NamespaceID = bson.ObjectIdHex(itemData.NamespaceID)
+		}
+
+		if _, err := reviewService.Park(reviewModel); err != nil {
+			logger.Error("Parking item for review failed", logging.FieldError, err, "name", itemData.NameEN)
+			return err
+		}
+		logger.Info("Parked item for review", "name", itemData.NameEN, "reason", reason)
+	}
+
+	return itemData.recordReview(reportService, itemData.NameEN, reason)
+}
+
+func CreateXivdbEventImporter(itemService item.Service, reportService importreport.Service, reviewService review.Service, mergePolicy item.MergePolicy, logger *logging.Logger) func(msg []byte) error {
+	logger = logger.With(logging.FieldTopic, "import.item.by.xivdbid")
+
+	return recordDryRunRejections(reportService, logger, tracing.Consume("import.item.by.xivdbid", func(ctx context.Context, msg []byte) error {
 		var itemData XivdbItemEventData
 		err := json.Unmarshal(msg, &itemData)
 
 		if err != nil {
-			fmt.Printf("Error(%v) unmarshaling event data: %v\n", err, string(msg))
+			logger.Error("Unmarshaling event data failed", logging.FieldError, err, "payload", string(msg))
 			return err
 		}
 
-		itemModel, err := itemService.FindByXivdbID(itemData.ID)
+		logger := itemData.logger(logger).With("xivdbId", itemData.ID)
+		itemService := item.WithContext(ctx, item.WithLogger(itemService, logger))
+
+		if !bson.IsObjectIdHex(itemData.NamespaceID) {
+			logger.Warn("Can not import an item without a namespace", "name", itemData.NameEN)
+			return errors.New("Item has no valid namespace")
+		}
+
+		if itemData.ID != 0 {
+			itemModel, err := itemService.FindByXivdbID(itemData.ID)
+			if err == nil {
+				return updateItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, itemModel, itemData)
+			}
+			if err.Error() != "not found" {
+				logger.Error("Finding item by xivdb id failed", logging.FieldError, err)
+				return err
+			}
+
+			return importUnlinkedXivdbItem(logger, itemService, reportService, reviewService, mergePolicy, itemData, msg)
+		}
+
+		itemModel, err := itemService.FindByName(bson.ObjectIdHex(itemData.NamespaceID), itemData.NameEN)
 
 		if err != nil {
 			if err.Error() == "not found" {
-				itemModel, err := itemService.FindByName(itemData.NameEN)
-
-				if err != nil {
-					if err.Error() == "not found" {
-						return createItemModelFromXivdbEvent(itemService, itemData)
-					}
-				} else {
-					return updateItemModelFromXivdbEvent(itemService, itemModel, itemData)
-				}
-			} else {
-				return err
+				return createItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, itemData)
 			}
+			logger.Error("Finding item by name failed", logging.FieldError, err, "name", itemData.NameEN)
+			return err
 		}
 
-		return updateItemModelFromXivdbEvent(itemService, itemModel, itemData)
-	}
+		return updateItemModelFromXivdbEvent(logger, itemService, reportService, mergePolicy, itemModel, itemData)
+	}))
 }
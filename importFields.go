@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/review"
+	"github.com/globalsign/mgo/bson"
+)
+
+// mappedFields lists the fields set on any of the mapped models, the namespace is written by every import
+func mappedFields(mappedModels []item.Model) []string {
+	fields := map[string]bool{"namespaceId": true}
+	for index := range mappedModels {
+		for _, diff := range item.Diff(&item.Model{}, &mappedModels[index]) {
+			fields[diff.Field] = true
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// rcItemFields lists the item fields the RC import of itemData writes
+func rcItemFields(itemData RCItemEventData) []string {
+	itemData.NamespaceID = bson.NewObjectId().Hex()
+	if itemData.GatheringJob != nil {
+		// the job is looked up by name on import, any id stands in for it here
+		itemData.GatheringJobID = bson.NewObjectId().Hex()
+	}
+
+	var mappedModel item.Model
+	setModelFromRCEvent(&mappedModel, itemData, nil)
+	return mappedFields([]item.Model{mappedModel})
+}
+
+// completeRCItemEvent carries every field of the RC event, the records of RC and of declarative sources may set any of them
+const completeRCItemEvent = `{
+	"name": "-", "names": {"en": "-"}, "description": "-", "descriptions": {"en": "-"},
+	"gatheringLevel": 1, "gatheringJob": "-", "gatheringEffort": 1, "price": 1, "priceHQ": 1,
+	"unspoiledNode": true, "unspoiledNodeTime": {}, "availableFromNpc": true
+}`
+
+// importSourceFields lists the item fields an import from source may write. Unlike item dumps their records are
+// not known up front, so the caller needs the write permission of every field the source can deliver.
+func importSourceFields(source string) []string {
+	if source == item.SourceXivdb {
+		// the XIVDB api only delivers names and descriptions, the catalog fields of the event come with item dumps
+		return itemDumpFields([]XivdbItemEventData{{
+			ID:     1,
+			NameEN: "-", NameDE: "-", NameFR: "-", NameJA: "-",
+			HelpEN: "-", HelpDE: "-", HelpFR: "-", HelpJA: "-",
+		}})
+	}
+
+	var itemData RCItemEventData
+	if err := json.Unmarshal([]byte(completeRCItemEvent), &itemData); err != nil {
+		panic(err)
+	}
+	return rcItemFields(itemData)
+}
+
+// reviewFields lists the item fields the parked import event of a review writes once it is replayed
+func reviewFields(model *review.Model) ([]string, error) {
+	if model.Topic != "import.item.by.xivdbid" {
+		return nil, fmt.Errorf("Unknown import topic: %v", model.Topic)
+	}
+
+	var itemData XivdbItemEventData
+	if err := json.Unmarshal([]byte(model.Payload), &itemData); err != nil {
+		return nil, err
+	}
+	return itemDumpFields([]XivdbItemEventData{itemData}), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/review"
+)
+
+func TestImportSourceFields(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{item.SourceXivdb, []string{"description", "descriptions", "name", "names", "namespaceId", "xivdbId"}},
+		{item.SourceRC, []string{
+			"availableFromNpc", "description", "descriptions", "gatheringEffort", "gatheringJobId", "gatheringLevel",
+			"name", "names", "namespaceId", "price", "priceHq", "unspoiledNode", "unspoiledNodeTime",
+		}},
+		{"garlandtools", importSourceFields(item.SourceRC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			if got := importSourceFields(tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importSourceFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewFields(t *testing.T) {
+	model := &review.Model{
+		Topic:   "import.item.by.xivdbid",
+		Payload: `{"id":5,"name_en":"Copper Ore","price":12,"namespace":"10112233445566778899aabb"}`,
+	}
+
+	got, err := reviewFields(model)
+	if err != nil {
+		t.Fatalf("reviewFields() error = %v", err)
+	}
+	want := []string{"name", "names", "namespaceId", "price", "xivdbId"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reviewFields() = %v, want %v", got, want)
+	}
+
+	if _, err := reviewFields(&review.Model{Topic: "import.item.by.rcname"}); err == nil {
+		t.Errorf("reviewFields() accepted a topic without reviews")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	importRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_import_runs_total",
+		Help: "Finished import runs by source and result",
+	}, []string{"source", "result"})
+
+	importRunsActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "itembackend_import_runs_active",
+		Help: "Import runs emitting events right now",
+	})
+
+	importItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_import_items_total",
+		Help: "Imported items by the action taken for them",
+	}, []string{"action", "dryRun"})
+
+	importQueueLag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "itembackend_import_queue_lag_seconds",
+		Help:    "Time import events spent between being emitted and being handled, replayed events keep their first emit time",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"topic"})
+)
+
+func init() {
+	prometheus.MustRegister(importRuns, importRunsActive, importItems, importQueueLag)
+}
+
+func countImportItem(action string, dryRun bool) {
+	importItems.WithLabelValues(action, strconv.FormatBool(dryRun)).Inc()
+}
+
+func finishImportRun(source string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	importRuns.WithLabelValues(source, result).Inc()
+}
+
+// measureQueueLag observes how long the events of topic waited in the queue, based on the emit time stamped by the import run
+func measureQueueLag(topic string, handler func(msg []byte) error) func(msg []byte) error {
+	return func(msg []byte) error {
+		var data importReporting
+		if json.Unmarshal(msg, &data) == nil && data.EmittedAt != nil {
+			lag := time.Since(*data.EmittedAt)
+			if lag < 0 {
+				lag = 0
+			}
+			importQueueLag.WithLabelValues(topic).Observe(lag.Seconds())
+		}
+
+		return handler(msg)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+)
+
+// importReporting is embedded into the import event data to tie every event to the report of its import run
+type importReporting struct {
+	ImportReportID string     `json:"importReportId,omitempty"`
+	DryRun         bool       `json:"dryRun,omitempty"`
+	EmittedAt      *time.Time `json:"emittedAt,omitempty"`
+}
+
+func newImportReporting(reportService importreport.Service, source string, dryRun bool) (importReporting, error) {
+	reporting := importReporting{DryRun: dryRun}
+
+	report, err := reportService.Create(source, dryRun)
+	if err != nil {
+		return reporting, err
+	}
+
+	reporting.ImportReportID = report.ID.Hex()
+	return reporting, nil
+}
+
+// logger adds the import run of the event to the lines of logger
+func (r importReporting) logger(logger *logging.Logger) *logging.Logger {
+	return logger.With(logging.FieldImportRunID, r.ImportReportID, "dryRun", r.DryRun)
+}
+
+// emitted stamps the reporting of an event about to be emitted, so its consumer can measure the queue lag
+func (r importReporting) emitted() importReporting {
+	now := time.Now().UTC()
+	r.EmittedAt = &now
+	return r
+}
+
+func (r importReporting) addTo(data map[string]interface{}) {
+	data["importReportId"] = r.ImportReportID
+	data["dryRun"] = r.DryRun
+	data["emittedAt"] = r.emitted().EmittedAt
+}
+
+func (r importReporting) recordCreate(reportService importreport.Service, itemModel *item.Model) error {
+	countImportItem(importreport.ActionCreate, r.DryRun)
+	if r.ImportReportID == "" {
+		return nil
+	}
+
+	if !r.DryRun {
+		return reportService.Increment(r.ImportReportID, importreport.ActionCreate)
+	}
+
+	return reportService.AddEntry(r.ImportReportID, &importreport.Entry{
+		Action: importreport.ActionCreate,
+		Name:   itemModel.Name,
+		Diffs:  item.Diff(&item.Model{}, itemModel),
+	})
+}
+
+func (r importReporting) recordUpdate(reportService importreport.Service, before *item.Model, after *item.Model, diffs []item.FieldDiff) error {
+	action := importreport.ActionUpdate
+	if len(diffs) == 0 {
+		action = importreport.ActionUnchanged
+	}
+
+	countImportItem(action, r.DryRun)
+	if r.ImportReportID == "" {
+		return nil
+	}
+
+	if !r.DryRun {
+		return reportService.Increment(r.ImportReportID, action)
+	}
+
+	itemID := before.ID
+	return reportService.AddEntry(r.ImportReportID, &importreport.Entry{
+		Action: action,
+		ItemID: &itemID,
+		Name:   after.Name,
+		Diffs:  diffs,
+	})
+}
+
+// recordReview counts an event that has been parked for review, dry runs list it with the reason instead
+func (r importReporting) recordReview(reportService importreport.Service, name string, reason string) error {
+	countImportItem(importreport.ActionReview, r.DryRun)
+	if r.ImportReportID == "" {
+		return nil
+	}
+
+	if !r.DryRun {
+		return reportService.Increment(r.ImportReportID, importreport.ActionReview)
+	}
+
+	return reportService.AddEntry(r.ImportReportID, &importreport.Entry{
+		Action: importreport.ActionReview,
+		Name:   name,
+		Error:  reason,
+	})
+}
+
+type importReportingEventData struct {
+	importReporting
+	Name   string `json:"name"`
+	NameEN string `json:"name_en"`
+}
+
+// recordDryRunRejections turns failures of dry run events into rejected report entries instead of failed messages
+func recordDryRunRejections(reportService importreport.Service, logger *logging.Logger, handler func(msg []byte) error) func(msg []byte) error {
+	return func(msg []byte) error {
+		err := handler(msg)
+		if err == nil {
+			return nil
+		}
+
+		var data importReportingEventData
+		json.Unmarshal(msg, &data)
+		if data.ImportReportID == "" || !data.DryRun {
+			return err
+		}
+
+		name := data.Name
+		if name == "" {
+			name = data.NameEN
+		}
+		countImportItem(importreport.ActionRejected, true)
+
+		reportErr := reportService.AddEntry(data.ImportReportID, &importreport.Entry{
+			Action: importreport.ActionRejected,
+			Name:   name,
+			Error:  err.Error(),
+		})
+		if reportErr != nil {
+			data.logger(logger).Error("Recording rejected dry run item failed", logging.FieldError, reportErr, "name", name)
+			return reportErr
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/reference"
+	"github.com/dukfaar/itemBackend/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrImportRunnerClosed = errors.New("Import runner is shutting down")
+	ErrImportRunnerBusy   = errors.New("Too many imports are running, try again later")
+)
+
+// ImportDependencies are the services an import job needs, handed over explicitly
+// instead of being looked up from the context of the request that started the job
+type ImportDependencies struct {
+	Bus           eventbus.EventBus
+	ReportService importreport.Service
+	References    *reference.Resolver
+	RCItemURL     string
+	Logger        *logging.Logger
+}
+
+type importJob func(ctx context.Context, namespaceId string, reporting importReporting) error
+
+// ImportRunner runs import jobs in the background with a lifecycle of its own: jobs outlive the request
+// that started them, at most MaxJobs of them run at once and Shutdown drains them before the service stops
+type ImportRunner struct {
+	deps ImportDependencies
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+	jobs   sync.WaitGroup
+	mutex  sync.Mutex
+	closed bool
+}
+
+func NewImportRunner(deps ImportDependencies, maxJobs int) *ImportRunner {
+	if maxJobs < 1 {
+		maxJobs = 1
+	}
+	if deps.Logger == nil {
+		deps.Logger = logging.Root()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ImportRunner{
+		deps:   deps,
+		ctx:    ctx,
+		cancel: cancel,
+		slots:  make(chan struct{}, maxJobs),
+	}
+}
+
+func (r *ImportRunner) acquire() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrImportRunnerClosed
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		return ErrImportRunnerBusy
+	}
+
+	r.jobs.Add(1)
+	return nil
+}
+
+func (r *ImportRunner) release() {
+	<-r.slots
+	r.jobs.Done()
+}
+
+// start reserves a slot, resolves the namespace and creates the import report before it returns,
+// so the caller learns about those failures right away. The job itself runs in the background
+// with a logger carrying the import run id in its context. It outlives the request of ctx,
+// so it is traced on its own, linked to the trace of ctx.
+func (r *ImportRunner) start(ctx context.Context, source string, namespace *string, dryRun bool, job importJob) (importReporting, error) {
+	reporting := importReporting{DryRun: dryRun}
+
+	if err := r.acquire(); err != nil {
+		return reporting, err
+	}
+
+	namespaceId, err := resolveImportNamespace(ctx, r.deps.References, namespace)
+	if err != nil {
+		r.release()
+		return reporting, err
+	}
+
+	reporting, err = newImportReporting(r.deps.ReportService, source, dryRun)
+	if err != nil {
+		r.release()
+		return reporting, err
+	}
+
+	logger := reporting.logger(r.deps.Logger).With("source", source, "namespaceId", namespaceId)
+
+	go func() {
+		defer r.release()
+
+		logger.Info("Import started")
+		started := time.Now()
+		importRunsActive.Inc()
+
+		jobCtx, span := tracing.Start(logging.NewContext(r.ctx, logger), "import "+source,
+			trace.WithLinks(trace.Link{SpanContext: trace.SpanContextFromContext(ctx)}),
+			trace.WithAttributes(
+				attribute.String("import.source", source),
+				attribute.String("import.run.id", reporting.ImportReportID),
+				attribute.Bool("import.dry_run", dryRun),
+			),
+		)
+		err := job(jobCtx, namespaceId, reporting)
+		tracing.End(span, err)
+		importRunsActive.Dec()
+		finishImportRun(source, err)
+		if err != nil {
+			logger.Error("Import failed", logging.FieldError, err, "duration", time.Since(started).Seconds())
+			return
+		}
+		logger.Info("Import finished", "duration", time.Since(started).Seconds())
+	}()
+
+	return reporting, nil
+}
+
+// Shutdown stops accepting jobs and waits for the running ones to finish. If ctx is done first,
+// the running jobs are cancelled and Shutdown returns once they stopped.
+func (r *ImportRunner) Shutdown(ctx context.Context) error {
+	r.mutex.Lock()
+	r.closed = true
+	r.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		r.jobs.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestImportRunner_Limit(t *testing.T) {
+	runner := NewImportRunner(ImportDependencies{}, 1)
+
+	if err := runner.acquire(); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if err := runner.acquire(); err != ErrImportRunnerBusy {
+		t.Errorf("acquire() error = %v, want %v", err, ErrImportRunnerBusy)
+	}
+
+	runner.release()
+	if err := runner.acquire(); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+	runner.release()
+}
+
+func TestImportRunner_ShutdownCancelsAfterTimeout(t *testing.T) {
+	runner := NewImportRunner(ImportDependencies{}, 1)
+
+	if err := runner.acquire(); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	go func() {
+		<-runner.ctx.Done()
+		runner.release()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := runner.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := runner.acquire(); err != ErrImportRunnerClosed {
+		t.Errorf("acquire() after Shutdown error = %v, want %v", err, ErrImportRunnerClosed)
+	}
+}
+
+func TestImportRunner_ShutdownDrains(t *testing.T) {
+	runner := NewImportRunner(ImportDependencies{}, 1)
+
+	if err := runner.acquire(); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		runner.release()
+	}()
+
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package importreport
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dukfaar/goUtils/permission"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/globalsign/mgo/bson"
+)
+
+var checkPermission = permission.Check
+
+// download is a complete report with all of its entries
+type download struct {
+	*Model
+	Entries []Entry `json:"entries"`
+}
+
+// Handler serves a complete report with all entries as a JSON download
+type Handler struct {
+	Service Service
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := checkPermission(r.Context(), "query.importReport")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if !bson.IsObjectIdHex(id) {
+		http.Error(w, "invalid report id", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Service.FindByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// the entries are collected before answering, so a failing iteration is reported instead of truncating the download
+	result := download{Model: report, Entries: make([]Entry, 0)}
+	err = h.Service.IterateEntries(id, func(entry *Entry) error {
+		result.Entries = append(result.Entries, *entry)
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Reading import report entries failed", logging.FieldError, err, logging.FieldImportRunID, id)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"import-report-"+id+".json\"")
+	w.Write(data)
+}
@@ -0,0 +1,83 @@
+package importreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const reportID = "000000000000000000000001"
+
+// memoryService serves a single report, its entries fail to load after failAfter entries if failAfter is set
+type memoryService struct {
+	Service
+	entries   []Entry
+	failAfter int
+}
+
+func (s *memoryService) FindByID(id string) (*Model, error) {
+	return &Model{ID: bson.ObjectIdHex(id), Source: "rc", Created: 2}, nil
+}
+
+func (s *memoryService) IterateEntries(id string, handler func(*Entry) error) error {
+	var entry Entry
+	for i := range s.entries {
+		if s.failAfter > 0 && i == s.failAfter {
+			return errors.New("cursor killed")
+		}
+		entry = s.entries[i]
+		if err := handler(&entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	original := checkPermission
+	checkPermission = func(ctx context.Context, name string) error { return nil }
+	defer func() { checkPermission = original }()
+
+	entries := []Entry{{Action: "created", Name: "Copper Ore"}, {Action: "created", Name: "Tin Ore"}}
+
+	tests := []struct {
+		name       string
+		failAfter  int
+		wantStatus int
+	}{
+		{"complete", 0, http.StatusOK},
+		{"failing halfway", 1, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &Handler{Service: &memoryService{entries: entries, failAfter: tt.failAfter}}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/importReport?id="+reportID, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("ServeHTTP() status = %v, want %v", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Source  string  `json:"source"`
+				Created int32   `json:"created"`
+				Entries []Entry `json:"entries"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("ServeHTTP() body %q is no report: %v", recorder.Body.String(), err)
+			}
+			if got.Source != "rc" || got.Created != 2 || len(got.Entries) != 2 || got.Entries[1].Name != "Tin Ore" {
+				t.Errorf("ServeHTTP() = %+v, want the report with both entries", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package importreport
+
+import (
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionRejected  = "rejected"
+	ActionReview    = "review"
+)
+
+type Model struct {
+	ID        bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Source    string        `json:"source" bson:"source"`
+	DryRun    bool          `json:"dryRun" bson:"dryRun"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	Created   int32         `json:"created" bson:"created"`
+	Updated   int32         `json:"updated" bson:"updated"`
+	Unchanged int32         `json:"unchanged" bson:"unchanged"`
+	Rejected  int32         `json:"rejected" bson:"rejected"`
+	Review    int32         `json:"review" bson:"review"`
+	Total     int32         `json:"total" bson:"total"`
+	Emitted   int32         `json:"emitted" bson:"emitted"`
+}
+
+type Entry struct {
+	ID       bson.ObjectId    `json:"_id,omitempty" bson:"_id,omitempty"`
+	ReportID bson.ObjectId    `json:"reportId" bson:"reportId"`
+	Action   string           `json:"action" bson:"action"`
+	ItemID   *bson.ObjectId   `json:"itemId,omitempty" bson:"itemId,omitempty"`
+	Name     string           `json:"name,omitempty" bson:"name,omitempty"`
+	Diffs    []item.FieldDiff `json:"diffs,omitempty" bson:"diffs,omitempty"`
+	Error    string           `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+var GraphQLType = `
+	type ImportReport {
+		_id: ID!
+		source: String!
+		dryRun: Boolean!
+		createdAt: String!
+		created: Int!
+		updated: Int!
+		unchanged: Int!
+		rejected: Int!
+		review: Int!
+		total: Int!
+		emitted: Int!
+		entries(action: String, first: Int, after: String): [ImportReportEntry!]!
+	}
+
+	type ImportReportEntry {
+		_id: ID!
+		action: String!
+		itemId: ID
+		name: String
+		diffs: [FieldDiff!]!
+		error: String
+	}
+
+	type FieldDiff {
+		field: String!
+		old: String
+		new: String
+	}
+`
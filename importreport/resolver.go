@@ -0,0 +1,141 @@
+package importreport
+
+import (
+	"context"
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Source() string {
+	return r.Model.Source
+}
+
+func (r *Resolver) DryRun() bool {
+	return r.Model.DryRun
+}
+
+func (r *Resolver) CreatedAt() string {
+	return r.Model.CreatedAt.Format(time.RFC3339)
+}
+
+func (r *Resolver) Created() int32 {
+	return r.Model.Created
+}
+
+func (r *Resolver) Updated() int32 {
+	return r.Model.Updated
+}
+
+func (r *Resolver) Unchanged() int32 {
+	return r.Model.Unchanged
+}
+
+func (r *Resolver) Rejected() int32 {
+	return r.Model.Rejected
+}
+
+func (r *Resolver) Review() int32 {
+	return r.Model.Review
+}
+
+func (r *Resolver) Total() int32 {
+	return r.Model.Total
+}
+
+func (r *Resolver) Emitted() int32 {
+	return r.Model.Emitted
+}
+
+func (r *Resolver) Entries(ctx context.Context, args struct {
+	Action *string
+	First  *int32
+	After  *string
+}) ([]*EntryResolver, error) {
+	reportService := ctx.Value("importReportService").(Service)
+
+	entries, err := reportService.ListEntries(r.Model.ID.Hex(), args.Action, args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*EntryResolver, len(entries))
+	for i := range entries {
+		result[i] = &EntryResolver{&entries[i]}
+	}
+	return result, nil
+}
+
+type EntryResolver struct {
+	entry *Entry
+}
+
+func (r *EntryResolver) ID() graphql.ID {
+	return graphql.ID(r.entry.ID.Hex())
+}
+
+func (r *EntryResolver) Action() string {
+	return r.entry.Action
+}
+
+func (r *EntryResolver) ItemID() *graphql.ID {
+	if r.entry.ItemID == nil {
+		return nil
+	}
+
+	id := graphql.ID(r.entry.ItemID.Hex())
+	return &id
+}
+
+func (r *EntryResolver) Name() *string {
+	if r.entry.Name == "" {
+		return nil
+	}
+	return &r.entry.Name
+}
+
+func (r *EntryResolver) Diffs() []*FieldDiffResolver {
+	result := make([]*FieldDiffResolver, len(r.entry.Diffs))
+	for i := range r.entry.Diffs {
+		result[i] = &FieldDiffResolver{&r.entry.Diffs[i]}
+	}
+	return result
+}
+
+func (r *EntryResolver) Error() *string {
+	if r.entry.Error == "" {
+		return nil
+	}
+	return &r.entry.Error
+}
+
+type FieldDiffResolver struct {
+	diff *item.FieldDiff
+}
+
+func (r *FieldDiffResolver) Field() string {
+	return r.diff.Field
+}
+
+func (r *FieldDiffResolver) Old() *string {
+	if r.diff.Old == "" {
+		return nil
+	}
+	return &r.diff.Old
+}
+
+func (r *FieldDiffResolver) New() *string {
+	if r.diff.New == "" {
+		return nil
+	}
+	return &r.diff.New
+}
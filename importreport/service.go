@@ -0,0 +1,148 @@
+package importreport
+
+import (
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type Service interface {
+	Create(source string, dryRun bool) (*Model, error)
+	FindByID(string) (*Model, error)
+	List(source *string, first *int32) ([]Model, error)
+	AddEntry(reportID string, entry *Entry) error
+	Increment(reportID string, action string) error
+	SetProgress(reportID string, emitted int32, total int32) error
+	ListEntries(reportID string, action *string, first *int32, after *string) ([]Entry, error)
+	IterateEntries(reportID string, handler func(*Entry) error) error
+}
+
+type MgoService struct {
+	db                *mgo.Database
+	collection        *mgo.Collection
+	entriesCollection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:                db,
+		collection:        db.C("importReports"),
+		entriesCollection: db.C("importReportEntries"),
+	}
+}
+
+func (s *MgoService) Create(source string, dryRun bool) (*Model, error) {
+	model := &Model{
+		ID:        bson.NewObjectId(),
+		Source:    source,
+		DryRun:    dryRun,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.collection.Insert(model)
+
+	return model, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) List(source *string, first *int32) ([]Model, error) {
+	query := bson.M{}
+	if source != nil {
+		query["source"] = *source
+	}
+
+	limit := 20
+	if first != nil {
+		limit = int(*first)
+	}
+
+	var result []Model
+	err := s.collection.Find(query).Sort("-createdAt").Limit(limit).All(&result)
+	return result, err
+}
+
+func counterForAction(action string) string {
+	switch action {
+	case ActionCreate:
+		return "created"
+	case ActionUpdate:
+		return "updated"
+	case ActionUnchanged:
+		return "unchanged"
+	case ActionReview:
+		return "review"
+	}
+	return "rejected"
+}
+
+func (s *MgoService) AddEntry(reportID string, entry *Entry) error {
+	entry.ID = bson.NewObjectId()
+	entry.ReportID = bson.ObjectIdHex(reportID)
+
+	err := s.entriesCollection.Insert(entry)
+	if err != nil {
+		return err
+	}
+
+	return s.Increment(reportID, entry.Action)
+}
+
+func (s *MgoService) Increment(reportID string, action string) error {
+	return s.collection.UpdateId(bson.ObjectIdHex(reportID), bson.M{
+		"$inc": bson.M{counterForAction(action): 1},
+	})
+}
+
+// SetProgress records how many items of an import run have been emitted so far, out of the total announced by the source
+func (s *MgoService) SetProgress(reportID string, emitted int32, total int32) error {
+	return s.collection.UpdateId(bson.ObjectIdHex(reportID), bson.M{
+		"$set": bson.M{"emitted": emitted, "total": total},
+	})
+}
+
+func (s *MgoService) makeEntriesQuery(reportID string, action *string) bson.M {
+	query := bson.M{"reportId": bson.ObjectIdHex(reportID)}
+	if action != nil {
+		query["action"] = *action
+	}
+	return query
+}
+
+func (s *MgoService) ListEntries(reportID string, action *string, first *int32, after *string) ([]Entry, error) {
+	query := s.makeEntriesQuery(reportID, action)
+	if after != nil {
+		query["_id"] = bson.M{"$gt": bson.ObjectIdHex(*after)}
+	}
+
+	limit := 100
+	if first != nil {
+		limit = int(*first)
+	}
+
+	var result []Entry
+	err := s.entriesCollection.Find(query).Sort("_id").Limit(limit).All(&result)
+	return result, err
+}
+
+func (s *MgoService) IterateEntries(reportID string, handler func(*Entry) error) error {
+	iter := s.entriesCollection.Find(s.makeEntriesQuery(reportID, nil)).Sort("_id").Iter()
+
+	var entry Entry
+	for iter.Next(&entry) {
+		if err := handler(&entry); err != nil {
+			iter.Close()
+			return err
+		}
+		entry = Entry{}
+	}
+
+	return iter.Close()
+}
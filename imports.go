@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/importsource"
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/reference"
+	"github.com/dukfaar/itemBackend/schedule"
+	"github.com/dukfaar/itemBackend/tracing"
+)
+
+const defaultImportNamespace = "FFXIV"
+
+func resolveImportNamespace(ctx context.Context, references *reference.Resolver, namespace *string) (string, error) {
+	references = references.WithContext(ctx)
+	if namespace == nil || *namespace == "" {
+		return references.ResolveNamespace(defaultImportNamespace)
+	}
+
+	return references.ResolveNamespace(*namespace)
+}
+
+// StartRC streams the RC item list into import events
+func (r *ImportRunner) StartRC(ctx context.Context, namespace *string, dryRun bool) (importReporting, error) {
+	return r.start(ctx, item.SourceRC, namespace, dryRun, func(ctx context.Context, namespaceId string, reporting importReporting) error {
+		client := NewRCItemClient(r.deps.RCItemURL)
+
+		return client.Stream(ctx, func(batch []map[string]interface{}, read int, total int) error {
+			for _, itemData := range batch {
+				itemData["namespace"] = namespaceId
+				reporting.addTo(itemData)
+				if err := tracing.Emit(ctx, r.deps.Bus, "import.item.by.rcname", itemData); err != nil {
+					return err
+				}
+			}
+
+			return r.deps.ReportService.SetProgress(reporting.ImportReportID, int32(read), int32(total))
+		})
+	})
+}
+
+// StartXivdb fetches the XIVDB item list and then every single item, throttled to stay within the api limits
+func (r *ImportRunner) StartXivdb(ctx context.Context, namespace *string, dryRun bool) (importReporting, error) {
+	return r.start(ctx, item.SourceXivdb, namespace, dryRun, func(ctx context.Context, namespaceId string, reporting importReporting) error {
+		itemList, err := FetchXivdbItemList(ctx)
+		if err != nil {
+			return err
+		}
+
+		logger := logging.FromContext(ctx).With(logging.FieldTopic, "import.item.by.xivdbid")
+
+		for index := range itemList {
+			listItem := itemList[index]
+			itemData, err := FetchXivdbItemData(ctx, listItem.ID)
+
+			if err != nil {
+				logger.Warn("Skipping item", "xivdbId", listItem.ID, logging.FieldError, err)
+			} else {
+				resultItem := make(map[string]interface{})
+				json.Unmarshal(itemData, &resultItem)
+				resultItem["namespace"] = namespaceId
+				reporting.addTo(resultItem)
+
+				delete(resultItem, "special_shops_obtain")
+				delete(resultItem, "special_shops_currency")
+
+				err = tracing.Emit(ctx, r.deps.Bus, "import.item.by.xivdbid", resultItem)
+
+				if err != nil {
+					logger.Error("Emitting import event failed", "xivdbId", listItem.ID, logging.FieldError, err)
+				}
+
+				r.deps.ReportService.SetProgress(reporting.ImportReportID, int32(index+1), int32(len(itemList)))
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond * 200):
+			}
+		}
+
+		return nil
+	})
+}
+
+// StartFile emits an already parsed item dump. Items without a name are skipped and do not count towards the total.
+func (r *ImportRunner) StartFile(ctx context.Context, itemList []XivdbItemEventData, namespace *string, dryRun bool) (importReporting, error) {
+	itemList, skipped := namedItems(itemList)
+
+	return r.start(ctx, item.SourceFile, namespace, dryRun, func(ctx context.Context, namespaceId string, reporting importReporting) error {
+		if skipped > 0 {
+			logging.FromContext(ctx).Warn("Skipping items without a name", "skipped", skipped)
+		}
+
+		EmitItemDump(ctx, r.deps.Bus, itemList, namespaceId, reporting)
+		return r.deps.ReportService.SetProgress(reporting.ImportReportID, int32(len(itemList)), int32(len(itemList)))
+	})
+}
+
+// StartSource runs a declarative import source. Its records go through the RC import pipeline,
+// with the name of the source as their provenance.
+func (r *ImportRunner) StartSource(ctx context.Context, source *importsource.Model, namespace *string, dryRun bool) (importReporting, error) {
+	return r.start(ctx, source.Name, namespace, dryRun, func(ctx context.Context, namespaceId string, reporting importReporting) error {
+		read := 0
+
+		return importsource.NewFetcher().Fetch(ctx, source, func(records []interface{}) error {
+			for _, record := range records {
+				itemData, err := source.Map(record)
+				if err != nil {
+					r.recordRejectedRecord(logging.FromContext(ctx), reporting, record, err)
+					continue
+				}
+
+				itemData["namespace"] = namespaceId
+				itemData["source"] = source.Name
+				reporting.addTo(itemData)
+				if err := tracing.Emit(ctx, r.deps.Bus, "import.item.by.rcname", itemData); err != nil {
+					return err
+				}
+			}
+
+			read += len(records)
+			return r.deps.ReportService.SetProgress(reporting.ImportReportID, int32(read), 0)
+		})
+	})
+}
+
+func (r *ImportRunner) recordRejectedRecord(logger *logging.Logger, reporting importReporting, record interface{}, err error) {
+	countImportItem(importreport.ActionRejected, reporting.DryRun)
+	encoded, _ := json.Marshal(record)
+
+	reportErr := r.deps.ReportService.AddEntry(reporting.ImportReportID, &importreport.Entry{
+		Action: importreport.ActionRejected,
+		Name:   string(encoded),
+		Error:  err.Error(),
+	})
+	if reportErr != nil {
+		logger.Error("Recording rejected record failed", logging.FieldError, reportErr, "record", string(encoded))
+	}
+}
+
+var schedulableImportSources = []string{item.SourceRC, item.SourceXivdb}
+
+func isSchedulableImportSource(source string) bool {
+	for _, candidate := range schedulableImportSources {
+		if candidate == source {
+			return true
+		}
+	}
+	return false
+}
+
+func triggerScheduledImport(runner *ImportRunner) schedule.TriggerFunc {
+	return func(model *schedule.Model) (string, error) {
+		var reporting importReporting
+		var err error
+
+		switch model.Source {
+		case item.SourceRC:
+			reporting, err = runner.StartRC(context.Background(), model.Namespace, model.DryRun)
+		case item.SourceXivdb:
+			reporting, err = runner.StartXivdb(context.Background(), model.Namespace, model.DryRun)
+		default:
+			return "", fmt.Errorf("Unknown import source: %v", model.Source)
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return reporting.ImportReportID, nil
+	}
+}
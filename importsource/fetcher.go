@@ -0,0 +1,202 @@
+package importsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 100
+	defaultMaxPages = 10000
+)
+
+type RecordHandler func(records []interface{}) error
+
+// Fetcher requests every page of an import source and hands the records of each page to a RecordHandler
+type Fetcher struct {
+	HTTPClient *http.Client
+	MaxPages   int
+}
+
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		HTTPClient: http.DefaultClient,
+		MaxPages:   defaultMaxPages,
+	}
+}
+
+func withDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func (p Pagination) pageSize() int {
+	if p.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return int(p.PageSize)
+}
+
+// pageParams returns the parameters of a page, page counts the requested pages starting at 0
+func (p Pagination) pageParams(page int, offset int, cursor string) map[string]interface{} {
+	params := make(map[string]interface{})
+
+	switch p.Strategy {
+	case PaginationOffset:
+		params[withDefault(p.OffsetParam, "offset")] = offset
+		params[withDefault(p.LimitParam, "limit")] = p.pageSize()
+	case PaginationPage:
+		params[withDefault(p.PageParam, "page")] = page + 1
+		params[withDefault(p.LimitParam, "limit")] = p.pageSize()
+	case PaginationCursor:
+		if cursor != "" {
+			params[withDefault(p.CursorParam, "cursor")] = cursor
+		}
+		params[withDefault(p.LimitParam, "limit")] = p.pageSize()
+	}
+
+	return params
+}
+
+func (f *Fetcher) newRequest(ctx context.Context, model *Model, params map[string]interface{}) (*http.Request, error) {
+	var request *http.Request
+
+	if model.Kind == KindGraphQL {
+		body, err := json.Marshal(map[string]interface{}{
+			"query":     model.Query,
+			"variables": params,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		request, err = http.NewRequest("POST", model.Endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json")
+	} else {
+		endpoint, err := url.Parse(model.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		query := endpoint.Query()
+		for key, value := range params {
+			query.Set(key, fmt.Sprint(value))
+		}
+		endpoint.RawQuery = query.Encode()
+
+		request, err = http.NewRequest("GET", endpoint.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	request.Header.Set("Accept", "application/json")
+	return request.WithContext(ctx), nil
+}
+
+func (f *Fetcher) fetchPage(ctx context.Context, model *Model, params map[string]interface{}) (interface{}, error) {
+	request, err := f.newRequest(ctx, model, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := f.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v answered with %v", model.Name, response.Status)
+	}
+
+	var body interface{}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	if model.Kind == KindGraphQL {
+		if errors, ok := Lookup(body, "errors"); ok {
+			if list, ok := errors.([]interface{}); ok && len(list) > 0 {
+				return nil, fmt.Errorf("%v answered with errors: %v", model.Name, list)
+			}
+		}
+	}
+
+	return body, nil
+}
+
+func cursorString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Fetch requests page after page until a page comes back short or empty, the cursor runs out or ctx is done
+func (f *Fetcher) Fetch(ctx context.Context, model *Model, handler RecordHandler) error {
+	maxPages := f.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	offset, cursor := 0, ""
+	for page := 0; page < maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		body, err := f.fetchPage(ctx, model, model.Pagination.pageParams(page, offset, cursor))
+		if err != nil {
+			return err
+		}
+
+		found, ok := Lookup(body, model.RecordsPath)
+		if !ok {
+			return fmt.Errorf("%v has nothing at %v", model.Name, model.RecordsPath)
+		}
+		records, ok := found.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v has no list at %v", model.Name, model.RecordsPath)
+		}
+
+		if len(records) > 0 {
+			if err := handler(records); err != nil {
+				return err
+			}
+		}
+
+		offset += len(records)
+
+		switch model.Pagination.Strategy {
+		case PaginationOffset, PaginationPage:
+			if len(records) < model.Pagination.pageSize() {
+				return nil
+			}
+		case PaginationCursor:
+			next, _ := Lookup(body, model.Pagination.CursorPath)
+			nextCursor := cursorString(next)
+			if len(records) == 0 || nextCursor == "" || nextCursor == cursor {
+				return nil
+			}
+			cursor = nextCursor
+		default:
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v did not stop paging after %v pages", model.Name, maxPages)
+}
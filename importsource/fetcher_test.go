@@ -0,0 +1,219 @@
+package importsource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var sheetRows = []map[string]interface{}{
+	{"Item": "Copper Ore", "Name DE": "Kupfererz", "Price": "12", "Job": "Miner", "Npc": "yes"},
+	{"Item": "Tin Ore", "Price": 7.0, "Job": "Miner", "Npc": "no"},
+	{"Item": "Iron Ore", "Price": "", "Job": "Miner"},
+}
+
+func newSheetServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		rows := make([]map[string]interface{}, 0)
+		for i := offset; i < len(sheetRows) && i < offset+limit; i++ {
+			rows = append(rows, sheetRows[i])
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"rows": rows},
+		})
+	}))
+}
+
+func newSheetSource(endpoint string) *Model {
+	return &Model{
+		Name:        "sheets",
+		Kind:        KindREST,
+		Endpoint:    endpoint,
+		RecordsPath: "data.rows",
+		Pagination: Pagination{
+			Strategy:    PaginationOffset,
+			PageSize:    2,
+			OffsetParam: "start",
+		},
+		Mapping: []FieldMapping{
+			{Field: "name", Path: "Item"},
+			{Field: "names.de", Path: "Name DE"},
+			{Field: "price", Path: "Price"},
+			{Field: "gatheringJobId", Path: "Job", Transform: TransformLookup},
+			{Field: "availableFromNpc", Path: "Npc"},
+			{Field: "gatheringEffort", Transform: TransformConstant, Value: "1"},
+		},
+	}
+}
+
+func TestFetcher_FetchAndMap(t *testing.T) {
+	server := newSheetServer(t)
+	defer server.Close()
+
+	source := newSheetSource(server.URL)
+	if err := source.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	mapped := make([]map[string]interface{}, 0)
+	pages := 0
+	err := NewFetcher().Fetch(context.Background(), source, func(records []interface{}) error {
+		pages++
+		for _, record := range records {
+			data, err := source.Map(record)
+			if err != nil {
+				return err
+			}
+			mapped = append(mapped, data)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(mapped) != 3 || pages != 2 {
+		t.Fatalf("Fetch() mapped %v records on %v pages, want 3 on 2", len(mapped), pages)
+	}
+
+	copper := mapped[0]
+	if copper["name"] != "Copper Ore" || copper["price"] != int32(12) || copper["gatheringJob"] != "Miner" ||
+		copper["availableFromNpc"] != true || copper["gatheringEffort"] != int32(1) {
+		t.Errorf("Map() = %v", copper)
+	}
+	if names, _ := copper["names"].(map[string]string); names["de"] != "Kupfererz" {
+		t.Errorf("Map() names = %v", copper["names"])
+	}
+
+	tin := mapped[1]
+	if tin["price"] != int32(7) || tin["availableFromNpc"] != false {
+		t.Errorf("Map() = %v", tin)
+	}
+	if _, ok := tin["names"]; ok {
+		t.Errorf("Map() set names of a record without them: %v", tin["names"])
+	}
+	if _, ok := mapped[2]["price"]; ok {
+		t.Errorf("Map() set the empty price of Iron Ore: %v", mapped[2]["price"])
+	}
+}
+
+func TestFetcher_GraphQLCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		page := map[string]interface{}{
+			"items":    []map[string]interface{}{{"name": "Copper Ore"}},
+			"nextPage": "2",
+		}
+		if request.Variables["after"] == "2" {
+			page = map[string]interface{}{
+				"items":    []map[string]interface{}{{"name": "Tin Ore"}},
+				"nextPage": nil,
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"items": page}})
+	}))
+	defer server.Close()
+
+	source := &Model{
+		Name:        "community",
+		Kind:        KindGraphQL,
+		Endpoint:    server.URL,
+		Query:       "query($after: String, $limit: Int) { items(after: $after, limit: $limit) { items { name } nextPage } }",
+		RecordsPath: "data.items.items",
+		Pagination: Pagination{
+			Strategy:    PaginationCursor,
+			CursorParam: "after",
+			CursorPath:  "data.items.nextPage",
+		},
+		Mapping: []FieldMapping{{Field: "name", Path: "name"}},
+	}
+	if err := source.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	names := make([]string, 0)
+	err := NewFetcher().Fetch(context.Background(), source, func(records []interface{}) error {
+		for _, record := range records {
+			data, err := source.Map(record)
+			if err != nil {
+				return err
+			}
+			names = append(names, data["name"].(string))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(names) != 2 || names[1] != "Tin Ore" {
+		t.Errorf("Fetch() = %v", names)
+	}
+}
+
+func TestModel_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Model)
+	}{
+		{"reserved name", func(m *Model) { m.Name = "rc" }},
+		{"unknown field", func(m *Model) { m.Mapping = append(m.Mapping, FieldMapping{Field: "weight", Path: "Weight"}) }},
+		{"lookup on a plain field", func(m *Model) {
+			m.Mapping = append(m.Mapping, FieldMapping{Field: "price", Path: "Price", Transform: TransformLookup})
+		}},
+		{"no name", func(m *Model) { m.Mapping = m.Mapping[1:] }},
+		{"unknown pagination", func(m *Model) { m.Pagination.Strategy = "links" }},
+		{"unknown transform", func(m *Model) { m.Mapping[2].Transform = "float" }},
+		{"transform to another type", func(m *Model) { m.Mapping[2].Transform = TransformBool }},
+		{"transform of a localized field", func(m *Model) { m.Mapping[1].Transform = TransformInt }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newSheetSource("http://localhost")
+			tt.modify(source)
+			if err := source.Validate(); err == nil {
+				t.Errorf("Validate() accepted %v", tt.name)
+			}
+		})
+	}
+}
+
+func TestModel_MapTransforms(t *testing.T) {
+	source := newSheetSource("http://localhost")
+	source.Mapping = []FieldMapping{
+		{Field: "name", Path: "Item", Transform: TransformString},
+		{Field: "names.de", Path: "Name DE", Transform: TransformString},
+		{Field: "price", Path: "Price", Transform: TransformInt},
+		{Field: "availableFromNpc", Path: "Npc", Transform: TransformBool},
+	}
+	if err := source.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	got, err := source.Map(sheetRows[0])
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"name":             "Copper Ore",
+		"names":            map[string]string{"de": "Kupfererz"},
+		"price":            int32(12),
+		"availableFromNpc": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,286 @@
+package importsource
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dukfaar/itemBackend/item"
+)
+
+const (
+	fieldTypeString = "string"
+	fieldTypeInt    = "int"
+	fieldTypeBool   = "bool"
+)
+
+type mappableField struct {
+	EventKey string
+	Type     string
+}
+
+// mappableFields are the item fields a source may fill, with the key of the RC import event that carries them.
+// The localized fields "names.<locale>" and "descriptions.<locale>" are handled on top of these.
+var mappableFields = map[string]mappableField{
+	"name":                             {"name", fieldTypeString},
+	"description":                      {"description", fieldTypeString},
+	"gatheringLevel":                   {"gatheringLevel", fieldTypeInt},
+	"gatheringJobId":                   {"gatheringJobId", fieldTypeString},
+	"gatheringEffort":                  {"gatheringEffort", fieldTypeInt},
+	"price":                            {"price", fieldTypeInt},
+	"priceHq":                          {"priceHQ", fieldTypeInt},
+	"unspoiledNode":                    {"unspoiledNode", fieldTypeBool},
+	"unspoiledNodeTime.time":           {"time", fieldTypeInt},
+	"unspoiledNodeTime.duration":       {"duration", fieldTypeInt},
+	"unspoiledNodeTime.ampm":           {"ampm", fieldTypeString},
+	"unspoiledNodeTime.folkloreNeeded": {"folkloreNeeded", fieldTypeString},
+	"availableFromNpc":                 {"availableFromNpc", fieldTypeBool},
+}
+
+var reservedNames = []string{item.SourceManual, item.SourceRC, item.SourceXivdb, item.SourceFile}
+
+func localizedField(field string) (string, string, bool) {
+	for _, prefix := range []string{"names", "descriptions"} {
+		if strings.HasPrefix(field, prefix+".") {
+			locale := item.NormalizeLocale(strings.TrimPrefix(field, prefix+"."))
+			return prefix, locale, locale != ""
+		}
+	}
+	return "", "", false
+}
+
+// fieldType is the type values of a field are coerced to, localized fields hold text
+func fieldType(field string) string {
+	if mappable, ok := mappableFields[field]; ok {
+		return mappable.Type
+	}
+	return fieldTypeString
+}
+
+func validateMapping(mapping FieldMapping) error {
+	if _, ok := mappableFields[mapping.Field]; !ok {
+		if _, _, ok := localizedField(mapping.Field); !ok {
+			return fmt.Errorf("Field %v can not be imported", mapping.Field)
+		}
+	}
+
+	switch mapping.Transform {
+	case TransformNone:
+	case TransformString, TransformInt, TransformBool:
+		if fieldType(mapping.Field) != mapping.Transform {
+			return fmt.Errorf("Field %v can not be converted to %v, it holds a %v", mapping.Field, mapping.Transform, fieldType(mapping.Field))
+		}
+	case TransformConstant:
+		return nil
+	case TransformLookup:
+		if mapping.Field != "gatheringJobId" {
+			return fmt.Errorf("Field %v does not reference anything to look up", mapping.Field)
+		}
+	default:
+		return fmt.Errorf("Unknown transform %v of field %v", mapping.Transform, mapping.Field)
+	}
+
+	if mapping.Path == "" {
+		return fmt.Errorf("Field %v needs a path", mapping.Field)
+	}
+
+	return nil
+}
+
+func (m *Model) Validate() error {
+	if m.Name == "" {
+		return errors.New("Import source needs a name")
+	}
+	for _, reserved := range reservedNames {
+		if m.Name == reserved {
+			return fmt.Errorf("Import source name %v is reserved", m.Name)
+		}
+	}
+
+	if m.Kind != KindREST && m.Kind != KindGraphQL {
+		return fmt.Errorf("Unknown import source kind %v", m.Kind)
+	}
+	if m.Kind == KindGraphQL && m.Query == "" {
+		return errors.New("GraphQL import sources need a query")
+	}
+
+	if endpoint, err := url.Parse(m.Endpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return fmt.Errorf("Invalid endpoint %v", m.Endpoint)
+	}
+
+	switch m.Pagination.Strategy {
+	case PaginationNone, PaginationOffset, PaginationPage:
+	case PaginationCursor:
+		if m.Pagination.CursorPath == "" {
+			return errors.New("Cursor pagination needs a cursor path")
+		}
+	default:
+		return fmt.Errorf("Unknown pagination strategy %v", m.Pagination.Strategy)
+	}
+
+	hasName := false
+	for _, mapping := range m.Mapping {
+		if err := validateMapping(mapping); err != nil {
+			return err
+		}
+		hasName = hasName || mapping.Field == "name"
+	}
+	if !hasName {
+		return errors.New("Import source has to map the name, items are matched by it")
+	}
+
+	return nil
+}
+
+// Lookup follows a dotted path like "data.items" or "$.rows.0.name" through decoded JSON
+func Lookup(data interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		switch value := data.(type) {
+		case map[string]interface{}:
+			next, ok := value[part]
+			if !ok {
+				return nil, false
+			}
+			data = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			data = value[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return data, true
+}
+
+func coerceString(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+	return "", fmt.Errorf("Can not convert %v to a string", value)
+}
+
+func coerceInt(value interface{}) (int32, error) {
+	switch value := value.(type) {
+	case float64:
+		return int32(value), nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("Can not convert %q to a number", value)
+		}
+		return int32(parsed), nil
+	}
+	return 0, fmt.Errorf("Can not convert %v to a number", value)
+}
+
+func coerceBool(value interface{}) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case float64:
+		return value != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "yes", "y", "x", "1":
+			return true, nil
+		case "false", "no", "n", "", "0":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("Can not convert %v to a boolean", value)
+}
+
+func coerce(value interface{}, fieldType string) (interface{}, error) {
+	switch fieldType {
+	case fieldTypeInt:
+		return coerceInt(value)
+	case fieldTypeBool:
+		return coerceBool(value)
+	}
+	return coerceString(value)
+}
+
+func setLocalized(data map[string]interface{}, key string, locale string, value string) {
+	values, ok := data[key].(map[string]string)
+	if !ok {
+		values = make(map[string]string)
+		data[key] = values
+	}
+	values[locale] = value
+}
+
+// Map turns a record into the data of an RC import event. Values are coerced to the type of their field,
+// fields whose path is missing or empty in the record are left out so the import keeps the current value.
+func (m *Model) Map(record interface{}) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	unspoiledNodeTime := make(map[string]interface{})
+
+	for _, mapping := range m.Mapping {
+		var value interface{} = mapping.Value
+		if mapping.Transform != TransformConstant {
+			found, ok := Lookup(record, mapping.Path)
+			if !ok || found == nil {
+				continue
+			}
+			if text, isText := found.(string); isText && strings.TrimSpace(text) == "" {
+				continue
+			}
+			value = found
+		}
+
+		if prefix, locale, ok := localizedField(mapping.Field); ok {
+			text, err := coerceString(value)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", mapping.Field, err)
+			}
+			setLocalized(data, prefix, locale, text)
+			continue
+		}
+
+		field := mappableFields[mapping.Field]
+		coerced, err := coerce(value, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", mapping.Field, err)
+		}
+
+		switch {
+		case mapping.Transform == TransformLookup:
+			data["gatheringJob"] = coerced
+		case strings.HasPrefix(mapping.Field, "unspoiledNodeTime."):
+			unspoiledNodeTime[field.EventKey] = coerced
+		default:
+			data[field.EventKey] = coerced
+		}
+	}
+
+	if len(unspoiledNodeTime) > 0 {
+		data["unspoiledNodeTime"] = unspoiledNodeTime
+	}
+
+	if name, _ := data["name"].(string); name == "" {
+		return nil, errors.New("Record has no name")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,118 @@
+package importsource
+
+import (
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	KindREST    = "rest"
+	KindGraphQL = "graphql"
+)
+
+const (
+	PaginationNone   = "none"
+	PaginationOffset = "offset"
+	PaginationPage   = "page"
+	PaginationCursor = "cursor"
+)
+
+// Values are coerced to the type of their field, TransformString, TransformInt and TransformBool state that type
+// explicitly and are only valid on fields of it
+const (
+	TransformNone     = ""
+	TransformString   = "string"
+	TransformInt      = "int"
+	TransformBool     = "bool"
+	TransformLookup   = "lookup"
+	TransformConstant = "constant"
+)
+
+// Pagination describes how to request the next page. For REST sources the parameters are added to the query string,
+// for GraphQL sources they are passed as variables of the query.
+type Pagination struct {
+	Strategy    string `json:"strategy" bson:"strategy"`
+	PageSize    int32  `json:"pageSize,omitempty" bson:"pageSize,omitempty"`
+	OffsetParam string `json:"offsetParam,omitempty" bson:"offsetParam,omitempty"`
+	LimitParam  string `json:"limitParam,omitempty" bson:"limitParam,omitempty"`
+	PageParam   string `json:"pageParam,omitempty" bson:"pageParam,omitempty"`
+	CursorParam string `json:"cursorParam,omitempty" bson:"cursorParam,omitempty"`
+	CursorPath  string `json:"cursorPath,omitempty" bson:"cursorPath,omitempty"`
+}
+
+// FieldMapping fills one item field (by its gql name, e.g. "price" or "names.de") from the record value at Path
+type FieldMapping struct {
+	Field     string `json:"field" bson:"field"`
+	Path      string `json:"path,omitempty" bson:"path,omitempty"`
+	Transform string `json:"transform,omitempty" bson:"transform,omitempty"`
+	Value     string `json:"value,omitempty" bson:"value,omitempty"`
+}
+
+// Model is a declarative import source. Its Name doubles as the import source of the provenance
+// and the import reports, so the merge policy can be configured per source.
+type Model struct {
+	ID          bson.ObjectId  `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name        string         `json:"name" bson:"name"`
+	Kind        string         `json:"kind" bson:"kind"`
+	Endpoint    string         `json:"endpoint" bson:"endpoint"`
+	Query       string         `json:"query,omitempty" bson:"query,omitempty"`
+	RecordsPath string         `json:"recordsPath" bson:"recordsPath"`
+	Pagination  Pagination     `json:"pagination" bson:"pagination"`
+	Mapping     []FieldMapping `json:"mapping" bson:"mapping"`
+}
+
+var GraphQLType = `
+	type ImportSource {
+		_id: ID!
+		name: String!
+		kind: String!
+		endpoint: String!
+		query: String
+		recordsPath: String!
+		pagination: ImportSourcePagination!
+		mapping: [ImportSourceFieldMapping!]!
+	}
+
+	type ImportSourcePagination {
+		strategy: String!
+		pageSize: Int
+		offsetParam: String
+		limitParam: String
+		pageParam: String
+		cursorParam: String
+		cursorPath: String
+	}
+
+	type ImportSourceFieldMapping {
+		field: String!
+		path: String
+		transform: String
+		value: String
+	}
+
+	input ImportSourceInput {
+		name: String!
+		kind: String!
+		endpoint: String!
+		query: String
+		recordsPath: String!
+		pagination: ImportSourcePaginationInput
+		mapping: [ImportSourceFieldMappingInput!]!
+	}
+
+	input ImportSourcePaginationInput {
+		strategy: String!
+		pageSize: Int
+		offsetParam: String
+		limitParam: String
+		pageParam: String
+		cursorParam: String
+		cursorPath: String
+	}
+
+	input ImportSourceFieldMappingInput {
+		field: String!
+		path: String
+		transform: String
+		value: String
+	}
+`
@@ -0,0 +1,178 @@
+package importsource
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Name() string {
+	return r.Model.Name
+}
+
+func (r *Resolver) Kind() string {
+	return r.Model.Kind
+}
+
+func (r *Resolver) Endpoint() string {
+	return r.Model.Endpoint
+}
+
+func (r *Resolver) Query() *string {
+	return optionalString(r.Model.Query)
+}
+
+func (r *Resolver) RecordsPath() string {
+	return r.Model.RecordsPath
+}
+
+func (r *Resolver) Pagination() *PaginationResolver {
+	return &PaginationResolver{&r.Model.Pagination}
+}
+
+func (r *Resolver) Mapping() []*FieldMappingResolver {
+	result := make([]*FieldMappingResolver, len(r.Model.Mapping))
+	for i := range r.Model.Mapping {
+		result[i] = &FieldMappingResolver{&r.Model.Mapping[i]}
+	}
+	return result
+}
+
+type PaginationResolver struct {
+	pagination *Pagination
+}
+
+func (r *PaginationResolver) Strategy() string {
+	return r.pagination.Strategy
+}
+
+func (r *PaginationResolver) PageSize() *int32 {
+	if r.pagination.PageSize == 0 {
+		return nil
+	}
+	return &r.pagination.PageSize
+}
+
+func (r *PaginationResolver) OffsetParam() *string {
+	return optionalString(r.pagination.OffsetParam)
+}
+
+func (r *PaginationResolver) LimitParam() *string {
+	return optionalString(r.pagination.LimitParam)
+}
+
+func (r *PaginationResolver) PageParam() *string {
+	return optionalString(r.pagination.PageParam)
+}
+
+func (r *PaginationResolver) CursorParam() *string {
+	return optionalString(r.pagination.CursorParam)
+}
+
+func (r *PaginationResolver) CursorPath() *string {
+	return optionalString(r.pagination.CursorPath)
+}
+
+type FieldMappingResolver struct {
+	mapping *FieldMapping
+}
+
+func (r *FieldMappingResolver) Field() string {
+	return r.mapping.Field
+}
+
+func (r *FieldMappingResolver) Path() *string {
+	return optionalString(r.mapping.Path)
+}
+
+func (r *FieldMappingResolver) Transform() *string {
+	return optionalString(r.mapping.Transform)
+}
+
+func (r *FieldMappingResolver) Value() *string {
+	return optionalString(r.mapping.Value)
+}
+
+type PaginationInput struct {
+	Strategy    string
+	PageSize    *int32
+	OffsetParam *string
+	LimitParam  *string
+	PageParam   *string
+	CursorParam *string
+	CursorPath  *string
+}
+
+type FieldMappingInput struct {
+	Field     string
+	Path      *string
+	Transform *string
+	Value     *string
+}
+
+type Input struct {
+	Name        string
+	Kind        string
+	Endpoint    string
+	Query       *string
+	RecordsPath string
+	Pagination  *PaginationInput
+	Mapping     []FieldMappingInput
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (i *Input) Model() *Model {
+	model := &Model{
+		Name:        i.Name,
+		Kind:        i.Kind,
+		Endpoint:    i.Endpoint,
+		Query:       valueOf(i.Query),
+		RecordsPath: i.RecordsPath,
+		Pagination:  Pagination{Strategy: PaginationNone},
+		Mapping:     make([]FieldMapping, len(i.Mapping)),
+	}
+
+	if i.Pagination != nil {
+		model.Pagination = Pagination{
+			Strategy:    i.Pagination.Strategy,
+			OffsetParam: valueOf(i.Pagination.OffsetParam),
+			LimitParam:  valueOf(i.Pagination.LimitParam),
+			PageParam:   valueOf(i.Pagination.PageParam),
+			CursorParam: valueOf(i.Pagination.CursorParam),
+			CursorPath:  valueOf(i.Pagination.CursorPath),
+		}
+		if i.Pagination.PageSize != nil {
+			model.Pagination.PageSize = *i.Pagination.PageSize
+		}
+	}
+
+	for index, mapping := range i.Mapping {
+		model.Mapping[index] = FieldMapping{
+			Field:     mapping.Field,
+			Path:      valueOf(mapping.Path),
+			Transform: valueOf(mapping.Transform),
+			Value:     valueOf(mapping.Value),
+		}
+	}
+
+	return model
+}
@@ -0,0 +1,96 @@
+package importsource
+
+import (
+	"fmt"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type Service interface {
+	Create(*Model) (*Model, error)
+	Update(id string, model *Model) (*Model, error)
+	DeleteByID(id string) (string, error)
+	FindByID(string) (*Model, error)
+	List() ([]Model, error)
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("importSources"),
+	}
+}
+
+func (s *MgoService) checkNameIsFree(name string, id bson.ObjectId) error {
+	count, err := s.collection.Find(bson.M{"name": name, "_id": bson.M{"$ne": id}}).Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("Import source %v already exists", name)
+	}
+
+	return nil
+}
+
+func (s *MgoService) Create(model *Model) (*Model, error) {
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	model.ID = bson.NewObjectId()
+	if err := s.checkNameIsFree(model.Name, model.ID); err != nil {
+		return nil, err
+	}
+
+	err := s.collection.Insert(model)
+
+	return model, err
+}
+
+func (s *MgoService) Update(id string, model *Model) (*Model, error) {
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	model.ID = bson.ObjectIdHex(id)
+	if err := s.checkNameIsFree(model.Name, model.ID); err != nil {
+		return nil, err
+	}
+
+	err := s.collection.UpdateId(model.ID, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (s *MgoService) DeleteByID(id string) (string, error) {
+	err := s.collection.RemoveId(bson.ObjectIdHex(id))
+
+	return id, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) List() ([]Model, error) {
+	var result []Model
+
+	err := s.collection.Find(bson.M{}).Sort("name").All(&result)
+
+	return result, err
+}
@@ -4,6 +4,7 @@ import "github.com/dukfaar/goUtils/relay"
 
 type ConnectionResolver struct {
 	Models []Model
+	Locale string
 	relay.ConnectionResolver
 }
 
@@ -11,7 +12,8 @@ func (r *ConnectionResolver) Edges() *[]*EdgeResolver {
 	l := make([]*EdgeResolver, len(r.Models))
 	for i := range r.Models {
 		l[i] = &EdgeResolver{
-			model: &r.Models[i],
+			model:  &r.Models[i],
+			locale: r.Locale,
 		}
 	}
 	return &l
@@ -0,0 +1,137 @@
+package item
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+type FieldDiff struct {
+	Field string `json:"field" bson:"field"`
+	Old   string `json:"old,omitempty" bson:"old,omitempty"`
+	New   string `json:"new,omitempty" bson:"new,omitempty"`
+}
+
+func copyInt32(value *int32) *int32 {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
+
+func copyBool(value *bool) *bool {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
+
+func copyString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
+
+func (t *LocalizedText) Clone() *LocalizedText {
+	if t == nil {
+		return nil
+	}
+
+	return &LocalizedText{
+		En: copyString(t.En),
+		De: copyString(t.De),
+		Fr: copyString(t.Fr),
+		Ja: copyString(t.Ja),
+	}
+}
+
+func (t *UnspoiledNodeTime) Clone() *UnspoiledNodeTime {
+	if t == nil {
+		return nil
+	}
+
+	return &UnspoiledNodeTime{
+		Time:           copyInt32(t.Time),
+		Duration:       copyInt32(t.Duration),
+		AmPm:           copyString(t.AmPm),
+		FolkloreNeeded: copyString(t.FolkloreNeeded),
+	}
+}
+
+// Clone returns a deep copy, so mapping import data onto the copy leaves the original untouched
+func (m *Model) Clone() *Model {
+	result := *m
+
+	result.Names = m.Names.Clone()
+	result.Description = copyString(m.Description)
+	result.Descriptions = m.Descriptions.Clone()
+	result.XivdbID = copyInt32(m.XivdbID)
+	result.GatheringLevel = copyInt32(m.GatheringLevel)
+	if m.GatheringJobID != nil {
+		gatheringJobID := *m.GatheringJobID
+		result.GatheringJobID = &gatheringJobID
+	}
+	result.GatheringEffort = copyInt32(m.GatheringEffort)
+	result.Price = copyInt32(m.Price)
+	result.PriceHQ = copyInt32(m.PriceHQ)
+	result.UnspoiledNode = copyBool(m.UnspoiledNode)
+	result.UnspoiledNodeTime = m.UnspoiledNodeTime.Clone()
+	result.AvailableFromNpc = copyBool(m.AvailableFromNpc)
+	if m.Provenance != nil {
+		result.Provenance = make(map[string]FieldProvenance, len(m.Provenance))
+		for field, provenance := range m.Provenance {
+			result.Provenance[field] = provenance
+		}
+	}
+
+	return &result
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return value.IsNil()
+	}
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+func encodeDiffValue(value reflect.Value) string {
+	if isEmptyValue(value) {
+		return ""
+	}
+
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// Diff lists every field, named by its gql tag, that differs between old and new. The _id is never compared.
+func Diff(old *Model, new *Model) []FieldDiff {
+	result := make([]FieldDiff, 0)
+
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(new).Elem()
+
+	forEachModelField(func(index int, field string) {
+		oldField, newField := oldValue.Field(index), newValue.Field(index)
+		if isEmptyValue(oldField) && isEmptyValue(newField) {
+			return
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			return
+		}
+
+		result = append(result, FieldDiff{
+			Field: field,
+			Old:   encodeDiffValue(oldField),
+			New:   encodeDiffValue(newField),
+		})
+	})
+
+	return result
+}
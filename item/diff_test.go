@@ -0,0 +1,36 @@
+package item
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	price := int32(10)
+	newPrice := int32(12)
+
+	old := newTestModel()
+	old.Price = &price
+
+	changed := old.Clone()
+	*changed.Price = newPrice
+	changed.Name = "ghi"
+
+	if *old.Price != price {
+		t.Fatalf("Clone() shares pointers with the original")
+	}
+
+	if diffs := Diff(old, old.Clone()); len(diffs) != 0 {
+		t.Errorf("Diff() of a clone = %v, want no diffs", diffs)
+	}
+
+	diffs := Diff(old, changed)
+	if len(diffs) != 2 {
+		t.Fatalf("Diff() = %v, want 2 diffs", diffs)
+	}
+	if diffs[0] != (FieldDiff{Field: "name", Old: `"def"`, New: `"ghi"`}) {
+		t.Errorf("Diff()[0] = %v", diffs[0])
+	}
+	if diffs[1] != (FieldDiff{Field: "price", Old: "10", New: "12"}) {
+		t.Errorf("Diff()[1] = %v", diffs[1])
+	}
+}
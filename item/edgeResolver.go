@@ -3,11 +3,12 @@ package item
 import graphql "github.com/graph-gophers/graphql-go"
 
 type EdgeResolver struct {
-	model *Model
+	model  *Model
+	locale string
 }
 
 func (r *EdgeResolver) Node() *Resolver {
-	return &Resolver{Model: r.model}
+	return &Resolver{Model: r.model, Locale: r.locale}
 }
 
 func (r *EdgeResolver) Cursor() graphql.ID {
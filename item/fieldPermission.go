@@ -0,0 +1,166 @@
+package item
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/dukfaar/goUtils/permission"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	FieldPermissionStrict = "strict"
+	FieldPermissionMask   = "mask"
+)
+
+// checkPermission is replaced in tests to observe which permissions a resolver asks for
+var checkPermission = permission.Check
+
+// ReadPermission names the permission guarding a field of an item type, e.g. Item.xivdbId.read
+func ReadPermission(typeName string, field string) string {
+	return typeName + "." + field + ".read"
+}
+
+// legacyReadPermissions maps read permissions to the names they were checked under before they were derived from the
+// gql tags, so existing grants of the old names keep working
+var legacyReadPermissions = map[string]string{
+	"Item.xivdbId.read": "Item.xivdbid.read",
+}
+
+// checkRead checks a read permission in the namespace, also under its legacy name
+func checkRead(ctx context.Context, name string, namespaceID bson.ObjectId) error {
+	err := CheckInNamespace(ctx, name, namespaceID)
+	if err == nil {
+		return nil
+	}
+
+	if legacyName, ok := legacyReadPermissions[name]; ok && CheckInNamespace(ctx, legacyName, namespaceID) == nil {
+		return nil
+	}
+	return err
+}
+
+// WritePermission names the permission needed to change a field of an item type, e.g. Item.price.write
+func WritePermission(typeName string, field string) string {
+	return typeName + "." + field + ".write"
+}
+
+// fieldPermissions maps the go field names of a type to the names of their graphql fields
+type fieldPermissions struct {
+	typeName string
+	fields   map[string]string
+}
+
+// newFieldPermissions reads the graphql field names from the gql tags, the same tags the graphql type is built from
+func newFieldPermissions(typeName string, t reflect.Type) fieldPermissions {
+	result := fieldPermissions{typeName: typeName, fields: map[string]string{}}
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("gql"); tag != "" {
+			result.fields[t.Field(i).Name] = tag
+		}
+	}
+	return result
+}
+
+var (
+	itemFieldPermissions              = newFieldPermissions("Item", reflect.TypeOf(Model{}))
+	unspoiledNodeTimeFieldPermissions = newFieldPermissions("UnspoiledNodeTime", reflect.TypeOf(UnspoiledNodeTime{}))
+	localizedTextFieldPermissions     = newFieldPermissions("LocalizedText", reflect.TypeOf(LocalizedText{}))
+)
+
+// ReadPermissions lists the read permissions of every field of Item, UnspoiledNodeTime and LocalizedText
+func ReadPermissions() []string {
+	result := make([]string, 0)
+	for _, permissions := range []fieldPermissions{itemFieldPermissions, unspoiledNodeTimeFieldPermissions, localizedTextFieldPermissions} {
+		for _, field := range permissions.fields {
+			result = append(result, ReadPermission(permissions.typeName, field))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WritableFields lists the graphql fields of Item that can be written, every field but the _id
+func WritableFields() []string {
+	result := make([]string, 0)
+	forEachModelField(func(index int, field string) {
+		result = append(result, field)
+	})
+	sort.Strings(result)
+	return result
+}
+
+// canRead checks the read permission of the field in the namespace of the item. A denied field is masked to null
+// if the request collects masked fields, otherwise the permission error is returned.
+func (p fieldPermissions) canRead(ctx context.Context, namespaceID bson.ObjectId, field string) (bool, error) {
+	gqlField, ok := p.fields[field]
+	if !ok {
+		panic("item: no gql field " + field)
+	}
+	name := ReadPermission(p.typeName, gqlField)
+
+	err := checkRead(ctx, name, namespaceID)
+	if err == nil {
+		return true, nil
+	}
+
+	if masked := MaskedFieldsFromContext(ctx); masked != nil {
+		masked.add(name)
+		return false, nil
+	}
+
+	return false, err
+}
+
+// MaskedFields collects the permissions of the fields that resolved to null during a request
+type MaskedFields struct {
+	mutex       sync.Mutex
+	permissions map[string]bool
+}
+
+func WithMaskedFields(ctx context.Context) (context.Context, *MaskedFields) {
+	masked := &MaskedFields{permissions: map[string]bool{}}
+	return context.WithValue(ctx, "maskedFields", masked), masked
+}
+
+// MaskedFieldsFromContext returns the collector of WithMaskedFields, nil if the denied fields of ctx are not masked
+func MaskedFieldsFromContext(ctx context.Context) *MaskedFields {
+	masked, _ := ctx.Value("maskedFields").(*MaskedFields)
+	return masked
+}
+
+func (m *MaskedFields) add(permission string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.permissions[permission] = true
+}
+
+// Permissions returns every masked permission once, sorted
+func (m *MaskedFields) Permissions() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make([]string, 0, len(m.permissions))
+	for name := range m.permissions {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Take returns the permissions masked since the last call like Permissions and forgets them,
+// for collectors that outlive a single result like the one of a graphql socket
+func (m *MaskedFields) Take() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make([]string, 0, len(m.permissions))
+	for name := range m.permissions {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	m.permissions = map[string]bool{}
+	return result
+}
@@ -0,0 +1,131 @@
+package item
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// denyPermissions makes every permission check fail and records the names that were checked
+func denyPermissions(t *testing.T) *[]string {
+	checked := make([]string, 0)
+	original := checkPermission
+	checkPermission = func(ctx context.Context, name string) error {
+		checked = append(checked, name)
+		return errors.New("permission denied")
+	}
+	t.Cleanup(func() { checkPermission = original })
+	return &checked
+}
+
+// findResolverMethod looks up the resolver method of a graphql field the way graphql-go matches them
+func findResolverMethod(resolver reflect.Value, field string) (reflect.Value, bool) {
+	name := strings.Replace(field, "_", "", -1)
+	for i := 0; i < resolver.NumMethod(); i++ {
+		if strings.EqualFold(resolver.Type().Method(i).Name, name) {
+			return resolver.Method(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func TestFieldPermissions_MatchSchema(t *testing.T) {
+	text := "abc"
+	model := newTestModel()
+	model.UnspoiledNodeTime = &UnspoiledNodeTime{AmPm: &text}
+
+	types := []struct {
+		name     string
+		model    reflect.Type
+		resolver interface{}
+	}{
+		{"Item", reflect.TypeOf(Model{}), &Resolver{Model: model}},
+		{"UnspoiledNodeTime", reflect.TypeOf(UnspoiledNodeTime{}), &UnspoiledNodeTimeResolver{model.UnspoiledNodeTime, model.NamespaceID}},
+		{"LocalizedText", reflect.TypeOf(LocalizedText{}), &LocalizedTextResolver{model.Names, model.NamespaceID}},
+	}
+
+	permissions := map[string]int{}
+	for _, name := range ReadPermissions() {
+		permissions[name]++
+	}
+
+	for _, tt := range types {
+		for i := 0; i < tt.model.NumField(); i++ {
+			field := tt.model.Field(i).Tag.Get("gql")
+			if field == "" {
+				continue
+			}
+
+			want := ReadPermission(tt.name, field)
+			if permissions[want] != 1 {
+				t.Errorf("%v.%v has %v read permissions named %v, want 1", tt.name, field, permissions[want], want)
+			}
+			delete(permissions, want)
+
+			method, ok := findResolverMethod(reflect.ValueOf(tt.resolver), field)
+			if !ok {
+				t.Errorf("%v.%v has no resolver", tt.name, field)
+				continue
+			}
+
+			// a denied global permission is checked again in the namespace of the item
+			checked := denyPermissions(t)
+			method.Call([]reflect.Value{reflect.ValueOf(context.Background())})
+			wantChecked := []string{want, NamespacePermission(model.NamespaceID, want)}
+			if !reflect.DeepEqual(*checked, wantChecked) {
+				t.Errorf("%v.%v checked %v, want %v", tt.name, field, *checked, wantChecked)
+			}
+		}
+	}
+
+	for name := range permissions {
+		t.Errorf("read permission %v has no schema field", name)
+	}
+}
+
+func TestResolver_DeniedField(t *testing.T) {
+	denyPermissions(t)
+	r := &Resolver{Model: newTestModel()}
+
+	got, err := r.Name(context.Background())
+	if err == nil || got != nil {
+		t.Errorf("Resolver.Name() = %v, %v, want a permission error", got, err)
+	}
+
+	ctx, masked := WithMaskedFields(context.Background())
+	got, err = r.Name(ctx)
+	if err != nil || got != nil {
+		t.Errorf("Resolver.Name() masked = %v, %v, want nil, nil", got, err)
+	}
+	r.Name(ctx)
+	r.PriceHQ(ctx)
+
+	want := []string{"Item.name.read", "Item.priceHq.read"}
+	if !reflect.DeepEqual(masked.Permissions(), want) {
+		t.Errorf("MaskedFields.Permissions() = %v, want %v", masked.Permissions(), want)
+	}
+
+	if got := MaskedFieldsFromContext(ctx).Take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("MaskedFields.Take() = %v, want %v", got, want)
+	}
+	if got := masked.Take(); len(got) != 0 {
+		t.Errorf("MaskedFields.Take() again = %v, want the permissions taken before forgotten", got)
+	}
+	if MaskedFieldsFromContext(context.Background()) != nil {
+		t.Errorf("MaskedFieldsFromContext() returned a collector without WithMaskedFields")
+	}
+}
+
+func TestResolver_LegacyReadPermission(t *testing.T) {
+	allowPermissions(t, "Item.xivdbid.read")
+	xivdbID := int32(5)
+	model := newTestModel()
+	model.XivdbID = &xivdbID
+
+	got, err := (&Resolver{Model: model}).XivdbID(context.Background())
+	if err != nil || got == nil || *got != 5 {
+		t.Errorf("Resolver.XivdbID() = %v, %v, want the grant of the old permission name to be accepted", got, err)
+	}
+}
@@ -0,0 +1,210 @@
+package item
+
+import (
+	"context"
+	"time"
+
+	"github.com/dukfaar/itemBackend/logging"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	serviceDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "itembackend_item_service_duration_seconds",
+		Help:    "Duration of the item service calls",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"method"})
+
+	serviceErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_item_service_errors_total",
+		Help: "Failed item service calls, items that were not found do not count as failures",
+	}, []string{"method"})
+
+	serviceDocuments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_item_service_documents_total",
+		Help: "Items returned by the item service calls",
+	}, []string{"method"})
+)
+
+func init() {
+	prometheus.MustRegister(serviceDuration, serviceErrors, serviceDocuments)
+}
+
+// InstrumentedService records the duration, the failures and the returned items of every call to Service.
+// The query builders do not touch the database and are passed on as they are.
+type InstrumentedService struct {
+	Service
+}
+
+func NewInstrumentedService(service Service) *InstrumentedService {
+	return &InstrumentedService{Service: service}
+}
+
+func observe(method string, start time.Time, err error, documents int) {
+	serviceDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil && err != mgo.ErrNotFound {
+		serviceErrors.WithLabelValues(method).Inc()
+	}
+	if documents > 0 {
+		serviceDocuments.WithLabelValues(method).Add(float64(documents))
+	}
+}
+
+func found(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+// WithLogger keeps the instrumentation around the service scoped to logger
+func (s *InstrumentedService) WithLogger(logger *logging.Logger) Service {
+	return &InstrumentedService{Service: WithLogger(s.Service, logger)}
+}
+
+func (s *InstrumentedService) WithContext(ctx context.Context) Service {
+	return &InstrumentedService{Service: WithContext(ctx, s.Service)}
+}
+
+func (s *InstrumentedService) Create(model *Model) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.Create(model)
+	observe("Create", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) CreateWithID(model *Model) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.CreateWithID(model)
+	observe("CreateWithID", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) CreateIfAbsent(selector bson.M, model *Model) (*Model, bool, error) {
+	start := time.Now()
+	result, created, err := s.Service.CreateIfAbsent(selector, model)
+	observe("CreateIfAbsent", start, err, 0)
+	return result, created, err
+}
+
+func (s *InstrumentedService) Update(id string, input interface{}) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.Update(id, input)
+	observe("Update", start, err, found(err))
+	return result, err
+}
+
+func (s *InstrumentedService) DeleteByID(id string) (string, error) {
+	start := time.Now()
+	result, err := s.Service.DeleteByID(id)
+	observe("DeleteByID", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) FindByID(id string) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.FindByID(id)
+	observe("FindByID", start, err, found(err))
+	return result, err
+}
+
+func (s *InstrumentedService) FindByName(namespaceID bson.ObjectId, name string) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.FindByName(namespaceID, name)
+	observe("FindByName", start, err, found(err))
+	return result, err
+}
+
+func (s *InstrumentedService) FindByXivdbID(id int32) (*Model, error) {
+	start := time.Now()
+	result, err := s.Service.FindByXivdbID(id)
+	observe("FindByXivdbID", start, err, found(err))
+	return result, err
+}
+
+func (s *InstrumentedService) NamespaceIDs() ([]bson.ObjectId, error) {
+	start := time.Now()
+	result, err := s.Service.NamespaceIDs()
+	observe("NamespaceIDs", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) HasElementBeforeID(id string) (bool, error) {
+	start := time.Now()
+	result, err := s.Service.HasElementBeforeID(id)
+	observe("HasElementBeforeID", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) HasElementAfterID(id string) (bool, error) {
+	start := time.Now()
+	result, err := s.Service.HasElementAfterID(id)
+	observe("HasElementAfterID", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) Count() (int, error) {
+	start := time.Now()
+	result, err := s.Service.Count()
+	observe("Count", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) HasElementBeforeIDWithQuery(query bson.M, id string) (bool, error) {
+	start := time.Now()
+	result, err := s.Service.HasElementBeforeIDWithQuery(query, id)
+	observe("HasElementBeforeIDWithQuery", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) HasElementAfterIDWithQuery(query bson.M, id string) (bool, error) {
+	start := time.Now()
+	result, err := s.Service.HasElementAfterIDWithQuery(query, id)
+	observe("HasElementAfterIDWithQuery", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) CountWithQuery(query bson.M) (int, error) {
+	start := time.Now()
+	result, err := s.Service.CountWithQuery(query)
+	observe("CountWithQuery", start, err, 0)
+	return result, err
+}
+
+func (s *InstrumentedService) PerformQuery(query bson.M) *Model {
+	start := time.Now()
+	result := s.Service.PerformQuery(query)
+	documents := 0
+	if result != nil {
+		documents = 1
+	}
+	observe("PerformQuery", start, nil, documents)
+	return result
+}
+
+func (s *InstrumentedService) PerformListQuery(query bson.M, first *int32, last *int32, before *string, after *string) ([]Model, error) {
+	start := time.Now()
+	result, err := s.Service.PerformListQuery(query, first, last, before, after)
+	observe("PerformListQuery", start, err, len(result))
+	return result, err
+}
+
+func (s *InstrumentedService) Iterate(query bson.M, handler func(*Model) error) error {
+	start := time.Now()
+	documents := 0
+	err := s.Service.Iterate(query, func(model *Model) error {
+		documents++
+		return handler(model)
+	})
+	observe("Iterate", start, err, documents)
+	return err
+}
+
+func (s *InstrumentedService) List(first *int32, last *int32, before *string, after *string) ([]Model, error) {
+	start := time.Now()
+	result, err := s.Service.List(first, last, before, after)
+	observe("List", start, err, len(result))
+	return result, err
+}
@@ -0,0 +1,83 @@
+package item
+
+import (
+	"errors"
+	"testing"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type fakeListService struct {
+	Service
+	models []Model
+	err    error
+}
+
+func (s *fakeListService) FindByID(id string) (*Model, error) {
+	return nil, s.err
+}
+
+func (s *fakeListService) Iterate(query bson.M, handler func(*Model) error) error {
+	for index := range s.models {
+		if err := handler(&s.models[index]); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestInstrumentedService_Iterate(t *testing.T) {
+	documents := serviceDocuments.WithLabelValues("Iterate")
+	before := counterValue(t, documents)
+
+	service := NewInstrumentedService(&fakeListService{models: []Model{*newTestModel(), *newTestModel()}})
+	visited := 0
+	err := service.Iterate(bson.M{}, func(model *Model) error {
+		visited++
+		return nil
+	})
+
+	if err != nil || visited != 2 {
+		t.Fatalf("InstrumentedService.Iterate() visited %v items, error = %v", visited, err)
+	}
+	if got := counterValue(t, documents) - before; got != 2 {
+		t.Errorf("InstrumentedService.Iterate() counted %v documents, want 2", got)
+	}
+}
+
+func TestInstrumentedService_FindByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantErrors float64
+	}{
+		{"missing items are no failures", mgo.ErrNotFound, 0},
+		{"failures", errors.New("connection lost"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := serviceErrors.WithLabelValues("FindByID")
+			before := counterValue(t, failures)
+
+			_, err := NewInstrumentedService(&fakeListService{err: tt.err}).FindByID("00112233445566778899aabb")
+
+			if err != tt.err {
+				t.Errorf("InstrumentedService.FindByID() error = %v, want %v", err, tt.err)
+			}
+			if got := counterValue(t, failures) - before; got != tt.wantErrors {
+				t.Errorf("InstrumentedService.FindByID() counted %v errors, want %v", got, tt.wantErrors)
+			}
+		})
+	}
+}
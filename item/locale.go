@@ -0,0 +1,120 @@
+package item
+
+import (
+	"strings"
+)
+
+var SupportedLocales = []string{"en", "de", "fr", "ja"}
+
+const DefaultLocale = "en"
+
+type LocalizedText struct {
+	En *string `json:"en,omitempty" bson:"en,omitempty" gql:"en"`
+	De *string `json:"de,omitempty" bson:"de,omitempty" gql:"de"`
+	Fr *string `json:"fr,omitempty" bson:"fr,omitempty" gql:"fr"`
+	Ja *string `json:"ja,omitempty" bson:"ja,omitempty" gql:"ja"`
+}
+
+func (t *LocalizedText) Get(locale string) *string {
+	if t == nil {
+		return nil
+	}
+
+	switch locale {
+	case "en":
+		return t.En
+	case "de":
+		return t.De
+	case "fr":
+		return t.Fr
+	case "ja":
+		return t.Ja
+	}
+
+	return nil
+}
+
+func (t *LocalizedText) Set(locale string, value string) {
+	if value == "" {
+		return
+	}
+
+	switch locale {
+	case "en":
+		t.En = &value
+	case "de":
+		t.De = &value
+	case "fr":
+		t.Fr = &value
+	case "ja":
+		t.Ja = &value
+	}
+}
+
+// Merge returns a copy of t with every locale that is set in other replaced by its value from other
+func (t *LocalizedText) Merge(other *LocalizedText) *LocalizedText {
+	result := &LocalizedText{}
+	if t != nil {
+		*result = *t
+	}
+
+	for _, locale := range SupportedLocales {
+		if value := other.Get(locale); value != nil {
+			result.Set(locale, *value)
+		}
+	}
+
+	if result.IsEmpty() {
+		return nil
+	}
+
+	return result
+}
+
+func (t *LocalizedText) IsEmpty() bool {
+	return t == nil || (t.En == nil && t.De == nil && t.Fr == nil && t.Ja == nil)
+}
+
+func LocalizedTextFromMap(values map[string]string) *LocalizedText {
+	result := &LocalizedText{}
+	for locale, value := range values {
+		result.Set(NormalizeLocale(locale), value)
+	}
+
+	if result.IsEmpty() {
+		return nil
+	}
+
+	return result
+}
+
+// NormalizeLocale reduces a language tag like "de-DE" to one of the SupportedLocales, or "" if it is not supported
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if index := strings.IndexAny(locale, "-_"); index >= 0 {
+		locale = locale[:index]
+	}
+
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return supported
+		}
+	}
+
+	return ""
+}
+
+// LocaleFromAcceptLanguage picks the first supported locale from an Accept-Language header, ignoring quality values
+func LocaleFromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		if index := strings.Index(part, ";"); index >= 0 {
+			part = part[:index]
+		}
+
+		if locale := NormalizeLocale(part); locale != "" {
+			return locale
+		}
+	}
+
+	return ""
+}
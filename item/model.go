@@ -18,6 +18,9 @@ type UnspoiledNodeTime struct {
 type Model struct {
 	ID                bson.ObjectId      `json:"_id,omitempty" bson:"_id,omitempty" gql:"_id"`
 	Name              string             `json:"name,omitempty" bson:"name,omitempty" gql:"name"`
+	Names             *LocalizedText     `json:"names,omitempty" bson:"names,omitempty" gql:"names"`
+	Description       *string            `json:"description,omitempty" bson:"description,omitempty" gql:"description"`
+	Descriptions      *LocalizedText     `json:"descriptions,omitempty" bson:"descriptions,omitempty" gql:"descriptions"`
 	NamespaceID       bson.ObjectId      `json:"namespaceId,omitempty" bson:"namespaceId,omitempty" gql:"namespaceId"`
 	XivdbID           *int32             `json:"xivdbid,omitempty" bson:"xivdbid,omitempty" gql:"xivdbId"`
 	GatheringLevel    *int32             `json:"gatheringLevel,omitempty" bson:"gatheringLevel,omitempty" gql:"gatheringLevel"`
@@ -28,8 +31,28 @@ type Model struct {
 	UnspoiledNode     *bool              `json:"unspoiledNode,omitempty" bson:"unspoiledNode,omitempty" gql:"unspoiledNode"`
 	UnspoiledNodeTime *UnspoiledNodeTime `json:"unspoiledNodeTime,omitempty" bson:"unspoiledNodeTime,omitempty" gql:"unspoiledNodeTime"`
 	AvailableFromNpc  *bool              `json:"availableFromNpc,omitempty" bson:"availableFromNpc,omitempty" gql:"availableFromNpc"`
+
+	Provenance map[string]FieldProvenance `json:"provenance,omitempty" bson:"provenance,omitempty"`
+}
+
+func (m *Model) LocalizedName(locale string) string {
+	if name := m.Names.Get(locale); name != nil {
+		return *name
+	}
+
+	return m.Name
+}
+
+func (m *Model) LocalizedDescription(locale string) *string {
+	if description := m.Descriptions.Get(locale); description != nil {
+		return description
+	}
+
+	return m.Description
 }
 
 var GraphQLType = graphql.Build(reflect.TypeOf((*UnspoiledNodeTime)(nil)).Elem(), "UnspoiledNodeTime") +
+	graphql.Build(reflect.TypeOf((*LocalizedText)(nil)).Elem(), "LocalizedText") +
 	graphql.Build(reflect.TypeOf((*Model)(nil)).Elem(), "Item") +
-	relay.GenerateConnectionTypes("Item")
+	relay.GenerateConnectionTypes("Item") +
+	PolicyGraphQLType
@@ -0,0 +1,54 @@
+package item
+
+import (
+	"context"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// NamespacePermission names a permission granted for the items of a single namespace, e.g. Item.price.write@<namespace id>
+func NamespacePermission(namespaceID bson.ObjectId, name string) string {
+	return name + "@" + namespaceID.Hex()
+}
+
+// CheckInNamespace passes if the permission is granted globally or for the namespace.
+// The error of the global check is returned if neither is granted.
+func CheckInNamespace(ctx context.Context, name string, namespaceID bson.ObjectId) error {
+	err := checkPermission(ctx, name)
+	if err == nil || !namespaceID.Valid() {
+		return err
+	}
+
+	if checkPermission(ctx, NamespacePermission(namespaceID, name)) == nil {
+		return nil
+	}
+
+	return err
+}
+
+// ReadableNamespaces lists the namespaces in which the permission is granted. A nil result without error means
+// the permission is granted globally, so nothing has to be filtered and listNamespaces is not called.
+func ReadableNamespaces(ctx context.Context, name string, listNamespaces func() ([]bson.ObjectId, error)) ([]bson.ObjectId, error) {
+	err := checkPermission(ctx, name)
+	if err == nil {
+		return nil, nil
+	}
+
+	namespaceIDs, listErr := listNamespaces()
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	result := make([]bson.ObjectId, 0)
+	for _, namespaceID := range namespaceIDs {
+		if checkPermission(ctx, NamespacePermission(namespaceID, name)) == nil {
+			result = append(result, namespaceID)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package item
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestCheckInNamespace(t *testing.T) {
+	ffxiv := bson.ObjectIdHex("10112233445566778899aabb")
+	other := bson.ObjectIdHex("20112233445566778899aabb")
+	allowPermissions(t, NamespacePermission(ffxiv, "query.updateItem"), NamespacePermission(ffxiv, "Item.price.write"))
+
+	if err := CheckInNamespace(context.Background(), "query.updateItem", ffxiv); err != nil {
+		t.Errorf("CheckInNamespace() in the curated namespace error = %v", err)
+	}
+	if err := CheckInNamespace(context.Background(), "query.updateItem", other); err == nil {
+		t.Errorf("CheckInNamespace() granted a permission in another namespace")
+	}
+	if err := CheckInNamespace(context.Background(), "query.updateItem", ""); err == nil {
+		t.Errorf("CheckInNamespace() granted a global permission")
+	}
+
+	if err := CheckWrite(context.Background(), ffxiv, []string{"price"}); err != nil {
+		t.Errorf("CheckWrite() in the curated namespace error = %v", err)
+	}
+	if err := CheckWrite(context.Background(), other, []string{"price"}); err == nil {
+		t.Errorf("CheckWrite() granted a write in another namespace")
+	}
+}
+
+func TestReadableNamespaces(t *testing.T) {
+	ffxiv := bson.ObjectIdHex("10112233445566778899aabb")
+	other := bson.ObjectIdHex("20112233445566778899aabb")
+	listNamespaces := func() ([]bson.ObjectId, error) {
+		return []bson.ObjectId{ffxiv, other}, nil
+	}
+
+	allowPermissions(t, NamespacePermission(ffxiv, "query.items"))
+	got, err := ReadableNamespaces(context.Background(), "query.items", listNamespaces)
+	if err != nil || !reflect.DeepEqual(got, []bson.ObjectId{ffxiv}) {
+		t.Errorf("ReadableNamespaces() = %v, %v, want [%v]", got, err, ffxiv)
+	}
+
+	allowPermissions(t, "query.items")
+	got, err = ReadableNamespaces(context.Background(), "query.items", func() ([]bson.ObjectId, error) {
+		t.Errorf("ReadableNamespaces() listed the namespaces of a global permission")
+		return nil, nil
+	})
+	if err != nil || got != nil {
+		t.Errorf("ReadableNamespaces() global = %v, %v, want nil, nil", got, err)
+	}
+
+	allowPermissions(t)
+	if _, err := ReadableNamespaces(context.Background(), "query.items", listNamespaces); err == nil {
+		t.Errorf("ReadableNamespaces() without any permission should fail")
+	}
+}
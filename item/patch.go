@@ -0,0 +1,104 @@
+package item
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// Patch holds the fields a mutation sets on an item, its gql tags match the fields of Item
+type Patch struct {
+	Name             *string `gql:"name"`
+	NamespaceId      *string `gql:"namespaceId"`
+	Description      *string `gql:"description"`
+	GatheringLevel   *int32  `gql:"gatheringLevel"`
+	GatheringJobId   *string `gql:"gatheringJobId"`
+	GatheringEffort  *int32  `gql:"gatheringEffort"`
+	Price            *int32  `gql:"price"`
+	PriceHq          *int32  `gql:"priceHq"`
+	UnspoiledNode    *bool   `gql:"unspoiledNode"`
+	AvailableFromNpc *bool   `gql:"availableFromNpc"`
+}
+
+// Fields lists the graphql fields the patch sets, sorted
+func (p *Patch) Fields() []string {
+	value := reflect.ValueOf(p).Elem()
+
+	result := make([]string, 0)
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Field(i).IsNil() {
+			result = append(result, value.Type().Field(i).Tag.Get("gql"))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (p *Patch) Apply(model *Model) {
+	if p.Name != nil {
+		model.Name = *p.Name
+	}
+	if p.NamespaceId != nil {
+		model.NamespaceID = bson.ObjectIdHex(*p.NamespaceId)
+	}
+	if p.Description != nil {
+		model.Description = copyString(p.Description)
+	}
+	if p.GatheringLevel != nil {
+		model.GatheringLevel = copyInt32(p.GatheringLevel)
+	}
+	if p.GatheringJobId != nil {
+		gatheringJobID := bson.ObjectIdHex(*p.GatheringJobId)
+		model.GatheringJobID = &gatheringJobID
+	}
+	if p.GatheringEffort != nil {
+		model.GatheringEffort = copyInt32(p.GatheringEffort)
+	}
+	if p.Price != nil {
+		model.Price = copyInt32(p.Price)
+	}
+	if p.PriceHq != nil {
+		model.PriceHQ = copyInt32(p.PriceHq)
+	}
+	if p.UnspoiledNode != nil {
+		model.UnspoiledNode = copyBool(p.UnspoiledNode)
+	}
+	if p.AvailableFromNpc != nil {
+		model.AvailableFromNpc = copyBool(p.AvailableFromNpc)
+	}
+}
+
+// ForbiddenFieldsError lists the fields a caller tried to write without permission
+type ForbiddenFieldsError struct {
+	Fields []string
+}
+
+func (e *ForbiddenFieldsError) Error() string {
+	return "No permission to write the item fields: " + strings.Join(e.Fields, ", ")
+}
+
+func (e *ForbiddenFieldsError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   "FORBIDDEN",
+		"fields": e.Fields,
+	}
+}
+
+// CheckWrite checks the write permission of every field in the namespace, a denial is reported for all offending fields at once
+func CheckWrite(ctx context.Context, namespaceID bson.ObjectId, fields []string) error {
+	forbidden := make([]string, 0)
+	for _, field := range fields {
+		if err := CheckInNamespace(ctx, WritePermission("Item", field), namespaceID); err != nil {
+			forbidden = append(forbidden, field)
+		}
+	}
+
+	if len(forbidden) > 0 {
+		return &ForbiddenFieldsError{Fields: forbidden}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package item
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// allowPermissions grants only the given permissions
+func allowPermissions(t *testing.T, allowed ...string) {
+	original := checkPermission
+	checkPermission = func(ctx context.Context, name string) error {
+		for _, permission := range allowed {
+			if permission == name {
+				return nil
+			}
+		}
+		return errors.New("permission denied")
+	}
+	t.Cleanup(func() { checkPermission = original })
+}
+
+func TestPatch_Apply(t *testing.T) {
+	name := "ghi"
+	price := int32(12)
+	patch := &Patch{Name: &name, Price: &price}
+
+	if got, want := patch.Fields(), []string{"name", "price"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Patch.Fields() = %v, want %v", got, want)
+	}
+
+	model := newTestModel()
+	patch.Apply(model)
+	if model.Name != "ghi" || *model.Price != 12 || model.Names == nil {
+		t.Errorf("Patch.Apply() = %+v", model)
+	}
+
+	price = 13
+	if *model.Price != 12 {
+		t.Errorf("Patch.Apply() shares the price with the patch")
+	}
+}
+
+func TestPatch_FieldsAreItemFields(t *testing.T) {
+	patchType := reflect.TypeOf(Patch{})
+	for i := 0; i < patchType.NumField(); i++ {
+		field := patchType.Field(i).Tag.Get("gql")
+		found := false
+		for _, itemField := range WritableFields() {
+			found = found || itemField == field
+		}
+		if !found {
+			t.Errorf("Patch.%v has no writable Item field %v", patchType.Field(i).Name, field)
+		}
+	}
+}
+
+func TestCheckWrite(t *testing.T) {
+	allowPermissions(t, "Item.price.write", "Item.priceHq.write")
+
+	if err := CheckWrite(context.Background(), "", []string{"price", "priceHq"}); err != nil {
+		t.Errorf("CheckWrite() price reporter error = %v", err)
+	}
+
+	err := CheckWrite(context.Background(), "", []string{"name", "price", "gatheringLevel"})
+	forbidden, ok := err.(*ForbiddenFieldsError)
+	if !ok {
+		t.Fatalf("CheckWrite() error = %v, want a ForbiddenFieldsError", err)
+	}
+	if want := []string{"name", "gatheringLevel"}; !reflect.DeepEqual(forbidden.Fields, want) {
+		t.Errorf("CheckWrite() forbidden = %v, want %v", forbidden.Fields, want)
+	}
+	if forbidden.Extensions()["code"] != "FORBIDDEN" {
+		t.Errorf("ForbiddenFieldsError.Extensions() = %v", forbidden.Extensions())
+	}
+}
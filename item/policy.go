@@ -0,0 +1,161 @@
+package item
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	SourceManual = "manual"
+	SourceRC     = "rc"
+	SourceXivdb  = "xivdb"
+	SourceFile   = "file"
+)
+
+type FieldProvenance struct {
+	Source    string    `json:"source" bson:"source"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	Locked    bool      `json:"locked,omitempty" bson:"locked,omitempty"`
+}
+
+// MergePolicy maps a field (by its gql name) to the import sources allowed to own it, highest priority first.
+// A source that is not listed may only fill the field while it is empty, fields without a policy go to the last writer.
+// Manual edits always win and lock the field against imports until it is unlocked.
+type MergePolicy map[string][]string
+
+var DefaultMergePolicy = MergePolicy{
+	"name":              {SourceXivdb, SourceFile},
+	"names":             {SourceXivdb, SourceFile},
+	"description":       {SourceXivdb, SourceFile},
+	"descriptions":      {SourceXivdb, SourceFile},
+	"xivdbId":           {SourceXivdb, SourceFile},
+	"gatheringLevel":    {SourceRC},
+	"gatheringJobId":    {SourceRC},
+	"gatheringEffort":   {SourceRC},
+	"price":             {SourceRC},
+	"priceHq":           {SourceRC},
+	"unspoiledNode":     {SourceRC},
+	"unspoiledNodeTime": {SourceRC},
+	"availableFromNpc":  {SourceRC},
+}
+
+// ParseMergePolicy reads a policy from JSON like {"price": ["rc"]}, overriding the matching fields of the DefaultMergePolicy
+func ParseMergePolicy(data string) (MergePolicy, error) {
+	result := MergePolicy{}
+	for field, sources := range DefaultMergePolicy {
+		result[field] = sources
+	}
+
+	if strings.TrimSpace(data) == "" {
+		return result, nil
+	}
+
+	overrides := MergePolicy{}
+	if err := json.Unmarshal([]byte(data), &overrides); err != nil {
+		return nil, err
+	}
+
+	for field, sources := range overrides {
+		result[field] = sources
+	}
+
+	return result, nil
+}
+
+func priority(sources []string, source string) int {
+	for index, candidate := range sources {
+		if candidate == source {
+			return index
+		}
+	}
+	return len(sources)
+}
+
+func (p MergePolicy) CanWrite(field string, source string, current *FieldProvenance) bool {
+	if source == SourceManual || current == nil {
+		return true
+	}
+
+	if current.Locked {
+		return false
+	}
+
+	if current.Source == source {
+		return true
+	}
+
+	sources, ok := p[field]
+	if !ok {
+		return true
+	}
+
+	return priority(sources, source) < len(sources) && priority(sources, source) <= priority(sources, current.Source)
+}
+
+func forEachModelField(handler func(index int, field string)) {
+	modelType := reflect.TypeOf(Model{})
+
+	for i := 0; i < modelType.NumField(); i++ {
+		field := strings.Split(modelType.Field(i).Tag.Get("gql"), ",")[0]
+		if field == "" || field == "_id" {
+			continue
+		}
+
+		handler(i, field)
+	}
+}
+
+// Merge applies every field of mapped that differs from current and that source is allowed to write,
+// recording the source as the provenance of the field. Neither current nor mapped is modified.
+func (p MergePolicy) Merge(current *Model, mapped *Model, source string, now time.Time) *Model {
+	result := current.Clone()
+	result.Provenance = make(map[string]FieldProvenance)
+	for field, provenance := range current.Provenance {
+		result.Provenance[field] = provenance
+	}
+
+	resultValue := reflect.ValueOf(result).Elem()
+	mappedValue := reflect.ValueOf(mapped).Elem()
+
+	forEachModelField(func(index int, field string) {
+		currentField, mappedField := resultValue.Field(index), mappedValue.Field(index)
+		if reflect.DeepEqual(currentField.Interface(), mappedField.Interface()) {
+			return
+		}
+
+		var provenance *FieldProvenance
+		if existing, ok := result.Provenance[field]; ok {
+			provenance = &existing
+		}
+
+		if provenance != nil && provenance.Locked && source != SourceManual {
+			return
+		}
+
+		if !isEmptyValue(currentField) && !p.CanWrite(field, source, provenance) {
+			return
+		}
+
+		currentField.Set(mappedField)
+		result.Provenance[field] = FieldProvenance{
+			Source:    source,
+			UpdatedAt: now,
+			Locked:    source == SourceManual,
+		}
+	})
+
+	if len(result.Provenance) == 0 {
+		result.Provenance = nil
+	}
+
+	return result
+}
+
+// Unlock drops the provenance of the given fields, so the next import may write them again
+func Unlock(model *Model, fields []string) {
+	for _, field := range fields {
+		delete(model.Provenance, field)
+	}
+}
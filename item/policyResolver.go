@@ -0,0 +1,80 @@
+package item
+
+import (
+	"sort"
+	"time"
+)
+
+var PolicyGraphQLType = `
+	type FieldMergePolicy {
+		field: String!
+		sources: [String!]!
+	}
+
+	type FieldProvenance {
+		field: String!
+		source: String!
+		updatedAt: String!
+		locked: Boolean!
+	}
+`
+
+type FieldMergePolicyResolver struct {
+	field   string
+	sources []string
+}
+
+func (r *FieldMergePolicyResolver) Field() string {
+	return r.field
+}
+
+func (r *FieldMergePolicyResolver) Sources() []string {
+	return r.sources
+}
+
+func (p MergePolicy) Resolvers() []*FieldMergePolicyResolver {
+	result := make([]*FieldMergePolicyResolver, 0, len(p))
+	for field, sources := range p {
+		result = append(result, &FieldMergePolicyResolver{field, sources})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].field < result[j].field
+	})
+
+	return result
+}
+
+type FieldProvenanceResolver struct {
+	field      string
+	provenance FieldProvenance
+}
+
+func (r *FieldProvenanceResolver) Field() string {
+	return r.field
+}
+
+func (r *FieldProvenanceResolver) Source() string {
+	return r.provenance.Source
+}
+
+func (r *FieldProvenanceResolver) UpdatedAt() string {
+	return r.provenance.UpdatedAt.Format(time.RFC3339)
+}
+
+func (r *FieldProvenanceResolver) Locked() bool {
+	return r.provenance.Locked
+}
+
+func ProvenanceResolvers(model *Model) []*FieldProvenanceResolver {
+	result := make([]*FieldProvenanceResolver, 0, len(model.Provenance))
+	for field, provenance := range model.Provenance {
+		result = append(result, &FieldProvenanceResolver{field, provenance})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].field < result[j].field
+	})
+
+	return result
+}
@@ -0,0 +1,44 @@
+package item
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergePolicy_Merge(t *testing.T) {
+	now := time.Now()
+	rcPrice := int32(10)
+	xivdbPrice := int32(12)
+
+	existing := DefaultMergePolicy.Merge(&Model{}, &Model{Name: "def", Price: &rcPrice}, SourceRC, now)
+	if existing.Provenance["price"].Source != SourceRC {
+		t.Fatalf("Merge() did not record the provenance of price: %v", existing.Provenance)
+	}
+
+	fromXivdb := existing.Clone()
+	fromXivdb.Name = "ghi"
+	fromXivdb.Price = &xivdbPrice
+	merged := DefaultMergePolicy.Merge(existing, fromXivdb, SourceXivdb, now)
+
+	if merged.Name != "ghi" {
+		t.Errorf("Merge() name = %v, want xivdb to own the name", merged.Name)
+	}
+	if *merged.Price != rcPrice {
+		t.Errorf("Merge() price = %v, want rc to keep the price", *merged.Price)
+	}
+
+	manual := merged.Clone()
+	manual.Name = "manual"
+	merged = DefaultMergePolicy.Merge(merged, manual, SourceManual, now)
+
+	fromXivdb = merged.Clone()
+	fromXivdb.Name = "ghi"
+	if DefaultMergePolicy.Merge(merged, fromXivdb, SourceXivdb, now).Name != "manual" {
+		t.Errorf("Merge() overwrote a manually locked field")
+	}
+
+	Unlock(merged, []string{"name"})
+	if DefaultMergePolicy.Merge(merged, fromXivdb, SourceXivdb, now).Name != "ghi" {
+		t.Errorf("Merge() did not write an unlocked field")
+	}
+}
@@ -0,0 +1,71 @@
+package item
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// ReadMask clears the fields of items the caller may not read, for the code paths that hand out
+// whole items without the field resolvers, e.g. exports. The checks are cached per namespace.
+type ReadMask struct {
+	ctx      context.Context
+	readable map[bson.ObjectId]map[string]bool
+}
+
+func NewReadMask(ctx context.Context) *ReadMask {
+	return &ReadMask{ctx: ctx, readable: map[bson.ObjectId]map[string]bool{}}
+}
+
+func (m *ReadMask) canRead(namespaceID bson.ObjectId, name string) bool {
+	permissions, ok := m.readable[namespaceID]
+	if !ok {
+		permissions = map[string]bool{}
+		m.readable[namespaceID] = permissions
+	}
+
+	readable, ok := permissions[name]
+	if !ok {
+		readable = checkRead(m.ctx, name, namespaceID) == nil
+		permissions[name] = readable
+	}
+	return readable
+}
+
+// clear sets every field of value the caller may not read to its zero value, value has to be a pointer to a struct
+func (m *ReadMask) clear(p fieldPermissions, namespaceID bson.ObjectId, value interface{}) {
+	fields := reflect.ValueOf(value).Elem()
+	for field, gqlField := range p.fields {
+		if !m.canRead(namespaceID, ReadPermission(p.typeName, gqlField)) {
+			target := fields.FieldByName(field)
+			target.Set(reflect.Zero(target.Type()))
+		}
+	}
+}
+
+func (m *ReadMask) localizedText(namespaceID bson.ObjectId, text *LocalizedText) *LocalizedText {
+	if text == nil {
+		return nil
+	}
+	result := *text
+	m.clear(localizedTextFieldPermissions, namespaceID, &result)
+	return &result
+}
+
+// Apply returns a copy of model without the fields the caller may not read, model itself is not modified
+func (m *ReadMask) Apply(model *Model) *Model {
+	namespaceID := model.NamespaceID
+	result := *model
+	m.clear(itemFieldPermissions, namespaceID, &result)
+
+	result.Names = m.localizedText(namespaceID, result.Names)
+	result.Descriptions = m.localizedText(namespaceID, result.Descriptions)
+	if result.UnspoiledNodeTime != nil {
+		unspoiledNodeTime := *result.UnspoiledNodeTime
+		m.clear(unspoiledNodeTimeFieldPermissions, namespaceID, &unspoiledNodeTime)
+		result.UnspoiledNodeTime = &unspoiledNodeTime
+	}
+
+	return &result
+}
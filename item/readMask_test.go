@@ -0,0 +1,31 @@
+package item
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadMask_Apply(t *testing.T) {
+	allowPermissions(t, "Item._id.read", "Item.name.read", "Item.names.read", "Item.namespaceId.read", "LocalizedText.en.read")
+
+	price := int32(12)
+	nameEN := "def"
+	model := newTestModel()
+	model.Price = &price
+	model.Names.En = &nameEN
+
+	got := NewReadMask(context.Background()).Apply(model)
+
+	if got.ID != model.ID || got.Name != "def" || got.NamespaceID != model.NamespaceID {
+		t.Errorf("ReadMask.Apply() = %+v, want the readable fields kept", got)
+	}
+	if got.Price != nil {
+		t.Errorf("ReadMask.Apply() price = %v, want it cleared", *got.Price)
+	}
+	if got.Names == nil || got.Names.En == nil || got.Names.De != nil {
+		t.Errorf("ReadMask.Apply() names = %+v, want only en kept", got.Names)
+	}
+	if model.Price == nil || model.Names.De == nil {
+		t.Errorf("ReadMask.Apply() modified the model")
+	}
+}
@@ -3,17 +3,17 @@ package item
 import (
 	"context"
 
-	"github.com/dukfaar/goUtils/permission"
+	"github.com/globalsign/mgo/bson"
 	graphql "github.com/graph-gophers/graphql-go"
 )
 
 type Resolver struct {
-	Model *Model
+	Model  *Model
+	Locale string
 }
 
 func (r *Resolver) ID(ctx context.Context) (*graphql.ID, error) {
-	err := permission.Check(ctx, "Item._id.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "ID"); !ok {
 		return nil, err
 	}
 
@@ -22,17 +22,48 @@ func (r *Resolver) ID(ctx context.Context) (*graphql.ID, error) {
 }
 
 func (r *Resolver) Name(ctx context.Context) (*string, error) {
-	err := permission.Check(ctx, "Item.name.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "Name"); !ok {
 		return nil, err
 	}
 
-	return &r.Model.Name, nil
+	name := r.Model.LocalizedName(r.Locale)
+	return &name, nil
+}
+
+func (r *Resolver) Names(ctx context.Context) (*LocalizedTextResolver, error) {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "Names"); !ok {
+		return nil, err
+	}
+
+	if r.Model.Names == nil {
+		return nil, nil
+	}
+
+	return &LocalizedTextResolver{r.Model.Names, r.Model.NamespaceID}, nil
+}
+
+func (r *Resolver) Description(ctx context.Context) (*string, error) {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "Description"); !ok {
+		return nil, err
+	}
+
+	return r.Model.LocalizedDescription(r.Locale), nil
+}
+
+func (r *Resolver) Descriptions(ctx context.Context) (*LocalizedTextResolver, error) {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "Descriptions"); !ok {
+		return nil, err
+	}
+
+	if r.Model.Descriptions == nil {
+		return nil, nil
+	}
+
+	return &LocalizedTextResolver{r.Model.Descriptions, r.Model.NamespaceID}, nil
 }
 
 func (r *Resolver) XivdbID(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "Item.xivdbid.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "XivdbID"); !ok {
 		return nil, err
 	}
 
@@ -40,8 +71,7 @@ func (r *Resolver) XivdbID(ctx context.Context) (*int32, error) {
 }
 
 func (r *Resolver) NamespaceID(ctx context.Context) (*graphql.ID, error) {
-	err := permission.Check(ctx, "Item.namespaceId.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "NamespaceID"); !ok {
 		return nil, err
 	}
 
@@ -50,8 +80,7 @@ func (r *Resolver) NamespaceID(ctx context.Context) (*graphql.ID, error) {
 }
 
 func (r *Resolver) GatheringLevel(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "Item.gatheringLevel.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "GatheringLevel"); !ok {
 		return nil, err
 	}
 
@@ -59,8 +88,7 @@ func (r *Resolver) GatheringLevel(ctx context.Context) (*int32, error) {
 }
 
 func (r *Resolver) GatheringJobID(ctx context.Context) (*graphql.ID, error) {
-	err := permission.Check(ctx, "Item.gatheringJobId.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "GatheringJobID"); !ok {
 		return nil, err
 	}
 
@@ -73,8 +101,7 @@ func (r *Resolver) GatheringJobID(ctx context.Context) (*graphql.ID, error) {
 }
 
 func (r *Resolver) GatheringEffort(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "Item.gatheringEffort.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "GatheringEffort"); !ok {
 		return nil, err
 	}
 
@@ -82,8 +109,7 @@ func (r *Resolver) GatheringEffort(ctx context.Context) (*int32, error) {
 }
 
 func (r *Resolver) Price(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "Item.price.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "Price"); !ok {
 		return nil, err
 	}
 
@@ -91,8 +117,7 @@ func (r *Resolver) Price(ctx context.Context) (*int32, error) {
 }
 
 func (r *Resolver) PriceHQ(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "Item.priceHq.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "PriceHQ"); !ok {
 		return nil, err
 	}
 
@@ -100,8 +125,7 @@ func (r *Resolver) PriceHQ(ctx context.Context) (*int32, error) {
 }
 
 func (r *Resolver) UnspoiledNode(ctx context.Context) (*bool, error) {
-	err := permission.Check(ctx, "Item.unspoiledNode.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "UnspoiledNode"); !ok {
 		return nil, err
 	}
 
@@ -109,8 +133,7 @@ func (r *Resolver) UnspoiledNode(ctx context.Context) (*bool, error) {
 }
 
 func (r *Resolver) AvailableFromNpc(ctx context.Context) (*bool, error) {
-	err := permission.Check(ctx, "Item.availableFromNpc.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "AvailableFromNpc"); !ok {
 		return nil, err
 	}
 
@@ -118,12 +141,12 @@ func (r *Resolver) AvailableFromNpc(ctx context.Context) (*bool, error) {
 }
 
 type UnspoiledNodeTimeResolver struct {
-	time *UnspoiledNodeTime
+	time        *UnspoiledNodeTime
+	namespaceID bson.ObjectId
 }
 
 func (r *Resolver) UnspoiledNodeTime(ctx context.Context) (*UnspoiledNodeTimeResolver, error) {
-	err := permission.Check(ctx, "Item.unspoiledNodeTime.read")
-	if err != nil {
+	if ok, err := itemFieldPermissions.canRead(ctx, r.Model.NamespaceID, "UnspoiledNodeTime"); !ok {
 		return nil, err
 	}
 
@@ -131,12 +154,11 @@ func (r *Resolver) UnspoiledNodeTime(ctx context.Context) (*UnspoiledNodeTimeRes
 		return nil, nil
 	}
 
-	return &UnspoiledNodeTimeResolver{r.Model.UnspoiledNodeTime}, nil
+	return &UnspoiledNodeTimeResolver{r.Model.UnspoiledNodeTime, r.Model.NamespaceID}, nil
 }
 
 func (r *UnspoiledNodeTimeResolver) Time(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "UnspoiledNodeTime.time.read")
-	if err != nil {
+	if ok, err := unspoiledNodeTimeFieldPermissions.canRead(ctx, r.namespaceID, "Time"); !ok {
 		return nil, err
 	}
 
@@ -144,8 +166,7 @@ func (r *UnspoiledNodeTimeResolver) Time(ctx context.Context) (*int32, error) {
 }
 
 func (r *UnspoiledNodeTimeResolver) Duration(ctx context.Context) (*int32, error) {
-	err := permission.Check(ctx, "UnspoiledNodeTime.duration.read")
-	if err != nil {
+	if ok, err := unspoiledNodeTimeFieldPermissions.canRead(ctx, r.namespaceID, "Duration"); !ok {
 		return nil, err
 	}
 
@@ -153,8 +174,7 @@ func (r *UnspoiledNodeTimeResolver) Duration(ctx context.Context) (*int32, error
 }
 
 func (r *UnspoiledNodeTimeResolver) AmPm(ctx context.Context) (*string, error) {
-	err := permission.Check(ctx, "UnspoiledNodeTime.ampm.read")
-	if err != nil {
+	if ok, err := unspoiledNodeTimeFieldPermissions.canRead(ctx, r.namespaceID, "AmPm"); !ok {
 		return nil, err
 	}
 
@@ -162,10 +182,46 @@ func (r *UnspoiledNodeTimeResolver) AmPm(ctx context.Context) (*string, error) {
 }
 
 func (r *UnspoiledNodeTimeResolver) FolkloreNeeded(ctx context.Context) (*string, error) {
-	err := permission.Check(ctx, "UnspoiledNodeTime.folkloreNeeded.read")
-	if err != nil {
+	if ok, err := unspoiledNodeTimeFieldPermissions.canRead(ctx, r.namespaceID, "FolkloreNeeded"); !ok {
 		return nil, err
 	}
 
 	return r.time.FolkloreNeeded, nil
 }
+
+type LocalizedTextResolver struct {
+	text        *LocalizedText
+	namespaceID bson.ObjectId
+}
+
+func (r *LocalizedTextResolver) En(ctx context.Context) (*string, error) {
+	if ok, err := localizedTextFieldPermissions.canRead(ctx, r.namespaceID, "En"); !ok {
+		return nil, err
+	}
+
+	return r.text.En, nil
+}
+
+func (r *LocalizedTextResolver) De(ctx context.Context) (*string, error) {
+	if ok, err := localizedTextFieldPermissions.canRead(ctx, r.namespaceID, "De"); !ok {
+		return nil, err
+	}
+
+	return r.text.De, nil
+}
+
+func (r *LocalizedTextResolver) Fr(ctx context.Context) (*string, error) {
+	if ok, err := localizedTextFieldPermissions.canRead(ctx, r.namespaceID, "Fr"); !ok {
+		return nil, err
+	}
+
+	return r.text.Fr, nil
+}
+
+func (r *LocalizedTextResolver) Ja(ctx context.Context) (*string, error) {
+	if ok, err := localizedTextFieldPermissions.canRead(ctx, r.namespaceID, "Ja"); !ok {
+		return nil, err
+	}
+
+	return r.text.Ja, nil
+}
@@ -1,6 +1,7 @@
 package item
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -8,6 +9,16 @@ import (
 	graphql "github.com/graph-gophers/graphql-go"
 )
 
+func newTestModel() *Model {
+	nameDE := "abc"
+	return &Model{
+		ID:          bson.ObjectIdHex("00112233445566778899aabb"),
+		Name:        "def",
+		Names:       &LocalizedText{De: &nameDE},
+		NamespaceID: bson.ObjectIdHex("10112233445566778899aabb"),
+	}
+}
+
 func TestResolver_ID(t *testing.T) {
 	tests := []struct {
 		name string
@@ -16,20 +27,18 @@ func TestResolver_ID(t *testing.T) {
 	}{
 		{
 			"",
-			&Resolver{
-				&Model{
-					bson.ObjectIdHex("00112233445566778899aabb"),
-					"def",
-					bson.ObjectIdHex("10112233445566778899aabb"),
-				},
-			},
+			&Resolver{Model: newTestModel()},
 			"00112233445566778899aabb",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := *tt.r.ID(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Resolver.ID() = %v, want %v", got, tt.want)
+			got, err := tt.r.ID(context.Background())
+			if err != nil {
+				t.Fatalf("Resolver.ID() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Resolver.ID() = %v, want %v", *got, tt.want)
 			}
 		})
 	}
@@ -42,21 +51,29 @@ func TestResolver_Name(t *testing.T) {
 		want string
 	}{
 		{
-			"",
-			&Resolver{
-				&Model{
-					bson.ObjectIdHex("00112233445566778899aabb"),
-					"def",
-					bson.ObjectIdHex("10112233445566778899aabb"),
-				},
-			},
+			"default",
+			&Resolver{Model: newTestModel()},
+			"def",
+		},
+		{
+			"localized",
+			&Resolver{Model: newTestModel(), Locale: "de"},
+			"abc",
+		},
+		{
+			"missing locale falls back",
+			&Resolver{Model: newTestModel(), Locale: "fr"},
 			"def",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := *tt.r.Name(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Resolver.Name() = %v, want %v", got, tt.want)
+			got, err := tt.r.Name(context.Background())
+			if err != nil {
+				t.Fatalf("Resolver.Name() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Resolver.Name() = %v, want %v", *got, tt.want)
 			}
 		})
 	}
@@ -70,20 +87,38 @@ func TestResolver_NamespaceID(t *testing.T) {
 	}{
 		{
 			"",
-			&Resolver{
-				&Model{
-					bson.ObjectIdHex("00112233445566778899aabb"),
-					"def",
-					bson.ObjectIdHex("10112233445566778899aabb"),
-				},
-			},
+			&Resolver{Model: newTestModel()},
 			"10112233445566778899aabb",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := *tt.r.NamespaceID(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Resolver.NamespaceID() = %v, want %v", got, tt.want)
+			got, err := tt.r.NamespaceID(context.Background())
+			if err != nil {
+				t.Fatalf("Resolver.NamespaceID() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Resolver.NamespaceID() = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"pt-BR, fr;q=0.7", "fr"},
+		{"JA", "ja"},
+		{"pt-BR", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := LocaleFromAcceptLanguage(tt.header); got != tt.want {
+				t.Errorf("LocaleFromAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
 			}
 		})
 	}
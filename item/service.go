@@ -1,18 +1,25 @@
 package item
 
 import (
+	"context"
+
 	"github.com/dukfaar/goUtils/eventbus"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/tracing"
 	mgo "github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
 type Service interface {
 	Create(*Model) (*Model, error)
+	CreateWithID(*Model) (*Model, error)
+	CreateIfAbsent(selector bson.M, model *Model) (*Model, bool, error)
 	Update(string, interface{}) (*Model, error)
 	DeleteByID(id string) (string, error)
 	FindByID(string) (*Model, error)
-	FindByName(string) (*Model, error)
+	FindByName(namespaceID bson.ObjectId, name string) (*Model, error)
 	FindByXivdbID(int32) (*Model, error)
+	NamespaceIDs() ([]bson.ObjectId, error)
 	HasElementBeforeID(id string) (bool, error)
 	HasElementAfterID(id string) (bool, error)
 
@@ -24,10 +31,12 @@ type Service interface {
 
 	MakeBaseQuery() bson.M
 	MakeNameRegexQuery(query bson.M, pattern string, options string)
+	MakeNameQuery(query bson.M, name string)
 	MakeListQuery(query bson.M, before *string, after *string)
 
 	PerformQuery(query bson.M) *Model
 	PerformListQuery(query bson.M, first *int32, last *int32, before *string, after *string) ([]Model, error)
+	Iterate(query bson.M, handler func(*Model) error) error
 
 	List(first *int32, last *int32, before *string, after *string) ([]Model, error)
 }
@@ -36,22 +45,87 @@ type MgoService struct {
 	db         *mgo.Database
 	collection *mgo.Collection
 	eventbus   eventbus.EventBus
+	logger     *logging.Logger
+	ctx        context.Context
 }
 
-func NewMgoService(db *mgo.Database, eventbus eventbus.EventBus) *MgoService {
+func NewMgoService(db *mgo.Database, eventbus eventbus.EventBus, logger *logging.Logger) *MgoService {
 	return &MgoService{
 		db:         db,
 		collection: db.C("items"),
 		eventbus:   eventbus,
+		logger:     logger,
+		ctx:        context.Background(),
+	}
+}
+
+// WithLogger returns the service logging with logger, e.g. one carrying the fields of a request
+func (s *MgoService) WithLogger(logger *logging.Logger) Service {
+	scoped := *s
+	scoped.logger = logger
+	return &scoped
+}
+
+type loggingService interface {
+	WithLogger(logger *logging.Logger) Service
+}
+
+// WithLogger scopes the logging of service to logger, services that do not log are returned as they are
+func WithLogger(service Service, logger *logging.Logger) Service {
+	if logged, ok := service.(loggingService); ok {
+		return logged.WithLogger(logger)
+	}
+	return service
+}
+
+// WithContext returns the service emitting its events as part of the trace of ctx
+func (s *MgoService) WithContext(ctx context.Context) Service {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
+}
+
+type contextService interface {
+	WithContext(ctx context.Context) Service
+}
+
+// WithContext scopes service to ctx, so its calls and events are traced as part of the request or event of ctx
+func WithContext(ctx context.Context, service Service) Service {
+	if scoped, ok := service.(contextService); ok {
+		return scoped.WithContext(ctx)
+	}
+	return service
+}
+
+// emit publishes an item event. A lost event leaves the other services out of date, so it is logged.
+func (s *MgoService) emit(topic string, payload interface{}, itemID string) {
+	logger := s.logger.With(logging.FieldTopic, topic, logging.FieldItemID, itemID)
+
+	if err := tracing.Emit(s.ctx, s.eventbus, topic, payload); err != nil {
+		logger.Error("Emitting item event failed", logging.FieldError, err)
+		return
 	}
+	logger.Debug("Emitted item event")
 }
 
 func (s *MgoService) MakeBaseQuery() bson.M {
 	return bson.M{}
 }
 
+func makeAnyLocaleNameQuery(value interface{}) []bson.M {
+	result := []bson.M{{"name": value}}
+	for _, locale := range SupportedLocales {
+		result = append(result, bson.M{"names." + locale: value})
+	}
+	return result
+}
+
 func (s *MgoService) MakeNameRegexQuery(query bson.M, pattern string, options string) {
-	query["name"] = bson.RegEx{Pattern: pattern, Options: options}
+	query["$or"] = makeAnyLocaleNameQuery(bson.RegEx{Pattern: pattern, Options: options})
+}
+
+func (s *MgoService) MakeNameQuery(query bson.M, name string) {
+	query["$or"] = makeAnyLocaleNameQuery(name)
 }
 
 func (s *MgoService) MakeListQuery(query bson.M, before *string, after *string) {
@@ -96,18 +170,100 @@ func (s *MgoService) PerformListQuery(query bson.M, first *int32, last *int32, b
 	return result, err
 }
 
+// Iterate hands every item matching query to handler in _id order, without loading them all at once
+func (s *MgoService) Iterate(query bson.M, handler func(*Model) error) error {
+	iter := s.collection.Find(query).Sort("_id").Iter()
+
+	var model Model
+	for iter.Next(&model) {
+		if err := handler(&model); err != nil {
+			iter.Close()
+			return err
+		}
+		model = Model{}
+	}
+
+	return iter.Close()
+}
+
 func (s *MgoService) Create(model *Model) (*Model, error) {
 	model.ID = bson.NewObjectId()
 
 	err := s.collection.Insert(model)
 
 	if err == nil {
-		s.eventbus.Emit("item.created", model)
+		s.emit("item.created", model, model.ID.Hex())
 	}
 
 	return model, err
 }
 
+// CreateWithID inserts model keeping its id, e.g. to bring back a deleted item from a snapshot
+func (s *MgoService) CreateWithID(model *Model) (*Model, error) {
+	err := s.collection.Insert(model)
+
+	if err == nil {
+		s.emit("item.created", model, model.ID.Hex())
+	}
+
+	return model, err
+}
+
+// EnsureIndexes creates the unique indexes that keep concurrent imports from creating the same item twice.
+// Names are unique within a namespace, the global name index of earlier versions is dropped.
+func (s *MgoService) EnsureIndexes() error {
+	err := s.collection.EnsureIndex(mgo.Index{
+		Key:        []string{"xivdbid"},
+		Unique:     true,
+		Sparse:     true,
+		Background: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	indexes, err := s.collection.Indexes()
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index.Name == "name_1" && index.Unique {
+			if err := s.collection.DropIndexName(index.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.collection.EnsureIndex(mgo.Index{
+		Key:           []string{"namespaceId", "name"},
+		Unique:        true,
+		PartialFilter: bson.M{"name": bson.M{"$exists": true}},
+		Background:    true,
+	})
+}
+
+// CreateIfAbsent atomically inserts model unless a document matching selector exists, in which case the existing document is returned.
+// The bool result tells whether model was inserted.
+func (s *MgoService) CreateIfAbsent(selector bson.M, model *Model) (*Model, bool, error) {
+	model.ID = bson.NewObjectId()
+
+	info, err := s.collection.Upsert(selector, bson.M{"$setOnInsert": model})
+
+	if err != nil && !mgo.IsDup(err) {
+		return nil, false, err
+	}
+
+	if err == nil && info.UpsertedId != nil {
+		s.emit("item.created", model, model.ID.Hex())
+		return model, true, nil
+	}
+
+	var existing Model
+	err = s.collection.Find(selector).One(&existing)
+
+	return &existing, false, err
+}
+
 func (s *MgoService) Update(id string, input interface{}) (*Model, error) {
 	err := s.collection.UpdateId(bson.ObjectIdHex(id), input)
 
@@ -121,7 +277,7 @@ func (s *MgoService) Update(id string, input interface{}) (*Model, error) {
 		return nil, err
 	}
 
-	s.eventbus.Emit("item.updated", result)
+	s.emit("item.updated", result, id)
 
 	return result, err
 }
@@ -130,7 +286,7 @@ func (s *MgoService) DeleteByID(id string) (string, error) {
 	err := s.collection.RemoveId(bson.ObjectIdHex(id))
 
 	if err == nil {
-		s.eventbus.Emit("item.deleted", id)
+		s.emit("item.deleted", id, id)
 	}
 
 	return id, err
@@ -144,10 +300,11 @@ func (s *MgoService) FindByID(id string) (*Model, error) {
 	return &result, err
 }
 
-func (s *MgoService) FindByName(name string) (*Model, error) {
+// FindByName finds an item by its name, names are only unique within a namespace
+func (s *MgoService) FindByName(namespaceID bson.ObjectId, name string) (*Model, error) {
 	var result Model
 
-	err := s.collection.Find(bson.M{"name": name}).One(&result)
+	err := s.collection.Find(bson.M{"namespaceId": namespaceID, "name": name}).One(&result)
 
 	return &result, err
 }
@@ -160,6 +317,15 @@ func (s *MgoService) FindByXivdbID(id int32) (*Model, error) {
 	return &result, err
 }
 
+// NamespaceIDs lists every namespace that holds at least one item
+func (s *MgoService) NamespaceIDs() ([]bson.ObjectId, error) {
+	var result []bson.ObjectId
+
+	err := s.collection.Find(bson.M{}).Distinct("namespaceId", &result)
+
+	return result, err
+}
+
 func (s *MgoService) HasElementBeforeID(id string) (bool, error) {
 	query := bson.M{}
 
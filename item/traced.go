@@ -0,0 +1,198 @@
+package item
+
+import (
+	"context"
+
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/tracing"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedService opens a span for every call to Service beneath the span of its context.
+// The wrapped service is scoped to the span, so the events it emits continue the trace.
+type TracedService struct {
+	Service
+	ctx context.Context
+}
+
+func NewTracedService(service Service) *TracedService {
+	return &TracedService{Service: service, ctx: context.Background()}
+}
+
+func (s *TracedService) WithContext(ctx context.Context) Service {
+	return &TracedService{Service: WithContext(ctx, s.Service), ctx: ctx}
+}
+
+func (s *TracedService) WithLogger(logger *logging.Logger) Service {
+	return &TracedService{Service: WithLogger(s.Service, logger), ctx: s.ctx}
+}
+
+// start opens the span of method and returns the wrapped service scoped to it
+func (s *TracedService) start(method string) (Service, trace.Span) {
+	ctx, span := tracing.Start(s.ctx, "items."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.collection.name", "items"),
+			attribute.String("db.operation.name", method),
+		),
+	)
+	return WithContext(ctx, s.Service), span
+}
+
+// endSpan finishes span, an item that was not found is an answer rather than a failure
+func endSpan(span trace.Span, err error, documents int) {
+	if err == mgo.ErrNotFound {
+		err = nil
+	}
+	span.SetAttributes(attribute.Int("db.response.returned_rows", documents))
+	tracing.End(span, err)
+}
+
+func (s *TracedService) Create(model *Model) (*Model, error) {
+	service, span := s.start("Create")
+	result, err := service.Create(model)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) CreateWithID(model *Model) (*Model, error) {
+	service, span := s.start("CreateWithID")
+	result, err := service.CreateWithID(model)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) CreateIfAbsent(selector bson.M, model *Model) (*Model, bool, error) {
+	service, span := s.start("CreateIfAbsent")
+	result, created, err := service.CreateIfAbsent(selector, model)
+	span.SetAttributes(attribute.Bool("item.created", created))
+	endSpan(span, err, 0)
+	return result, created, err
+}
+
+func (s *TracedService) Update(id string, input interface{}) (*Model, error) {
+	service, span := s.start("Update")
+	span.SetAttributes(attribute.String("item.id", id))
+	result, err := service.Update(id, input)
+	endSpan(span, err, found(err))
+	return result, err
+}
+
+func (s *TracedService) DeleteByID(id string) (string, error) {
+	service, span := s.start("DeleteByID")
+	span.SetAttributes(attribute.String("item.id", id))
+	result, err := service.DeleteByID(id)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) FindByID(id string) (*Model, error) {
+	service, span := s.start("FindByID")
+	span.SetAttributes(attribute.String("item.id", id))
+	result, err := service.FindByID(id)
+	endSpan(span, err, found(err))
+	return result, err
+}
+
+func (s *TracedService) FindByName(namespaceID bson.ObjectId, name string) (*Model, error) {
+	service, span := s.start("FindByName")
+	result, err := service.FindByName(namespaceID, name)
+	endSpan(span, err, found(err))
+	return result, err
+}
+
+func (s *TracedService) FindByXivdbID(id int32) (*Model, error) {
+	service, span := s.start("FindByXivdbID")
+	result, err := service.FindByXivdbID(id)
+	endSpan(span, err, found(err))
+	return result, err
+}
+
+func (s *TracedService) NamespaceIDs() ([]bson.ObjectId, error) {
+	service, span := s.start("NamespaceIDs")
+	result, err := service.NamespaceIDs()
+	endSpan(span, err, len(result))
+	return result, err
+}
+
+func (s *TracedService) HasElementBeforeID(id string) (bool, error) {
+	service, span := s.start("HasElementBeforeID")
+	result, err := service.HasElementBeforeID(id)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) HasElementAfterID(id string) (bool, error) {
+	service, span := s.start("HasElementAfterID")
+	result, err := service.HasElementAfterID(id)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) Count() (int, error) {
+	service, span := s.start("Count")
+	result, err := service.Count()
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) HasElementBeforeIDWithQuery(query bson.M, id string) (bool, error) {
+	service, span := s.start("HasElementBeforeIDWithQuery")
+	result, err := service.HasElementBeforeIDWithQuery(query, id)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) HasElementAfterIDWithQuery(query bson.M, id string) (bool, error) {
+	service, span := s.start("HasElementAfterIDWithQuery")
+	result, err := service.HasElementAfterIDWithQuery(query, id)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) CountWithQuery(query bson.M) (int, error) {
+	service, span := s.start("CountWithQuery")
+	result, err := service.CountWithQuery(query)
+	endSpan(span, err, 0)
+	return result, err
+}
+
+func (s *TracedService) PerformQuery(query bson.M) *Model {
+	service, span := s.start("PerformQuery")
+	result := service.PerformQuery(query)
+	documents := 0
+	if result != nil {
+		documents = 1
+	}
+	endSpan(span, nil, documents)
+	return result
+}
+
+func (s *TracedService) PerformListQuery(query bson.M, first *int32, last *int32, before *string, after *string) ([]Model, error) {
+	service, span := s.start("PerformListQuery")
+	result, err := service.PerformListQuery(query, first, last, before, after)
+	endSpan(span, err, len(result))
+	return result, err
+}
+
+func (s *TracedService) Iterate(query bson.M, handler func(*Model) error) error {
+	service, span := s.start("Iterate")
+	documents := 0
+	err := service.Iterate(query, func(model *Model) error {
+		documents++
+		return handler(model)
+	})
+	endSpan(span, err, documents)
+	return err
+}
+
+func (s *TracedService) List(first *int32, last *int32, before *string, after *string) ([]Model, error) {
+	service, span := s.start("List")
+	result, err := service.List(first, last, before, after)
+	endSpan(span, err, len(result))
+	return result, err
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dukfaar/itemBackend/item"
+)
+
+func AddAcceptLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := item.LocaleFromAcceptLanguage(r.Header.Get("Accept-Language"))
+		if locale != "" {
+			r = r.WithContext(context.WithValue(r.Context(), "locale", locale))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func resolveLocale(ctx context.Context, locale *string) string {
+	if locale != nil {
+		if result := item.NormalizeLocale(*locale); result != "" {
+			return result
+		}
+	}
+
+	if result, ok := ctx.Value("locale").(string); ok {
+		return result
+	}
+
+	return ""
+}
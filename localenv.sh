@@ -2,6 +2,7 @@ PORT=3002
 
 export DB_HOST=localhost:27017
 export NSQD_TCP_URL=localhost:4150
+export NSQD_HTTP_URL=localhost:4151
 export NSQLOOKUP_HTTP_URL=localhost:4161
 export PORT=${PORT}
 export PUBLISHED_HOSTNAME=localhost
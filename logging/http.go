@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID keeps the request id set by the api gateway, so a request can be followed across services
+func RequestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); id != "" && len(id) <= 64 && !strings.ContainsAny(id, "\r\n") {
+		return id
+	}
+
+	random := make([]byte, 12)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+// UserID reads the subject of a jwt access token. The signature is not checked, the request has been authenticated
+// before. Other tokens are identified by a fingerprint that never reveals the token itself.
+func UserID(r *http.Request) string {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" {
+		return ""
+	}
+
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Subject string `json:"sub"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Subject != "" {
+				return claims.Subject
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:6])
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands sockets over, they are logged as switching protocols
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Connection can not be hijacked")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Handler gives every request a logger derived from logger carrying the request and user id.
+// The request id is sent back, so callers can quote it when reporting a problem.
+func Handler(logger *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := RequestID(r)
+		requestLogger := logger.With(FieldRequestID, requestID, FieldUserID, UserID(r))
+
+		w.Header().Set(RequestIDHeader, requestID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, r.WithContext(NewContext(r.Context(), requestLogger)))
+
+		requestLogger.Debug("request handled",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration", time.Since(start).Seconds(),
+		)
+	})
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserID(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-1"}`))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+	}{
+		{"anonymous", "", ""},
+		{"jwt", "Bearer header." + payload + ".signature", "user-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/graphql", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if got := UserID(r); got != tt.want {
+				t.Errorf("UserID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("POST", "/graphql", nil)
+	r.Header.Set("Authorization", "Bearer opaque-secret")
+	if got := UserID(r); !strings.HasPrefix(got, "token:") || strings.Contains(got, "opaque-secret") {
+		t.Errorf("UserID() = %q, want a fingerprint of the token", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var output bytes.Buffer
+	logger := New(&output, LevelDebug)
+
+	handler := Handler(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("Handling")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	r := httptest.NewRequest("POST", "/graphql", nil)
+	r.Header.Set(RequestIDHeader, "gateway-id")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got := w.Header().Get(RequestIDHeader); got != "gateway-id" {
+		t.Errorf("%v = %q, want the id of the gateway", RequestIDHeader, got)
+	}
+
+	lines := decodeLines(t, &output)
+	if len(lines) != 2 {
+		t.Fatalf("logged %v lines, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if line[FieldRequestID] != "gateway-id" {
+			t.Errorf("line %v misses the request id", line)
+		}
+	}
+	if lines[1]["status"] != float64(http.StatusTeapot) {
+		t.Errorf("request line status = %v", lines[1]["status"])
+	}
+}
@@ -0,0 +1,177 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(value string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(value, name) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("Unknown log level %q, expected one of %v", value, strings.Join(levelNames, ", "))
+}
+
+// The fields every log line of a request, an import run or an event carries as far as they are known
+const (
+	FieldRequestID   = "requestId"
+	FieldImportRunID = "importRunId"
+	FieldUserID      = "userId"
+	FieldTopic       = "topic"
+	FieldItemID      = "itemId"
+	FieldError       = "error"
+)
+
+type output struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// Logger writes leveled log lines as json objects. Loggers derived by With share the level and the output
+// of the logger they are derived from, so changing the level at runtime affects all of them.
+type Logger struct {
+	level  *int32
+	out    *output
+	fields []interface{}
+}
+
+func New(writer io.Writer, level Level) *Logger {
+	levelValue := int32(level)
+	return &Logger{
+		level: &levelValue,
+		out:   &output{writer: writer},
+	}
+}
+
+var root = New(os.Stdout, LevelInfo)
+
+// Root is the logger of the process, the loggers handed to the services are derived from it
+func Root() *Logger {
+	return root
+}
+
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(l.level))
+}
+
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a logger adding the key value pairs keyvals to every line. Empty values are left out.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]interface{}, len(l.fields), len(l.fields)+len(keyvals))
+	copy(fields, l.fields)
+
+	return &Logger{
+		level:  l.level,
+		out:    l.out,
+		fields: append(fields, keyvals...),
+	}
+}
+
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(LevelDebug, msg, keyvals)
+}
+
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.log(LevelInfo, msg, keyvals)
+}
+
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(LevelWarn, msg, keyvals)
+}
+
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.log(LevelError, msg, keyvals)
+}
+
+func (l *Logger) log(level Level, msg string, keyvals []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	line := map[string]interface{}{
+		"time":  time.Now().UTC().Format(time.RFC3339Nano),
+		"level": level.String(),
+		"msg":   msg,
+	}
+	addFields(line, l.fields)
+	addFields(line, keyvals)
+
+	encoded, err := json.Marshal(line)
+	if err != nil {
+		encoded, _ = json.Marshal(map[string]interface{}{
+			"time":  line["time"],
+			"level": line["level"],
+			"msg":   msg,
+			"error": "Unable to encode log fields: " + err.Error(),
+		})
+	}
+
+	l.out.mutex.Lock()
+	defer l.out.mutex.Unlock()
+	l.out.writer.Write(append(encoded, '\n'))
+}
+
+func addFields(line map[string]interface{}, keyvals []interface{}) {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+
+		switch value := keyvals[i+1].(type) {
+		case nil:
+		case string:
+			if value != "" {
+				line[key] = value
+			}
+		case error:
+			line[key] = value.Error()
+		default:
+			line[key] = value
+		}
+	}
+}
+
+// NewContext hands logger to everything running with the returned context
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, "logger", logger)
+}
+
+// FromContext returns the logger of ctx, or the root logger if ctx has none
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value("logger").(*Logger); ok {
+		return logger
+	}
+	return root
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("log line %q is not json: %v", line, err)
+		}
+		result = append(result, decoded)
+	}
+	return result
+}
+
+func TestLogger_Fields(t *testing.T) {
+	var output bytes.Buffer
+	logger := New(&output, LevelDebug).With(FieldRequestID, "abc", FieldUserID, "")
+
+	logger.With(FieldItemID, "42").Error("Updating item failed", FieldError, errors.New("broken"), "attempt", 2)
+
+	lines := decodeLines(t, &output)
+	if len(lines) != 1 {
+		t.Fatalf("logged %v lines, want 1", len(lines))
+	}
+
+	want := map[string]interface{}{
+		"level":        "error",
+		"msg":          "Updating item failed",
+		FieldRequestID: "abc",
+		FieldItemID:    "42",
+		FieldError:     "broken",
+		"attempt":      float64(2),
+	}
+	for key, value := range want {
+		if lines[0][key] != value {
+			t.Errorf("line[%q] = %v, want %v", key, lines[0][key], value)
+		}
+	}
+	if _, ok := lines[0][FieldUserID]; ok {
+		t.Errorf("line has the empty user id")
+	}
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	var output bytes.Buffer
+	logger := New(&output, LevelInfo)
+	derived := logger.With(FieldTopic, "import.item.by.rcname")
+
+	derived.Debug("hidden")
+	logger.SetLevel(LevelDebug)
+	derived.Debug("shown")
+	logger.SetLevel(LevelError)
+	derived.Warn("hidden")
+
+	lines := decodeLines(t, &output)
+	if len(lines) != 1 || lines[0]["msg"] != "shown" {
+		t.Errorf("logged %v, want only the line logged at debug level", lines)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if got, err := ParseLevel(strings.ToUpper(level.String())); err != nil || got != level {
+			t.Errorf("ParseLevel(%q) = %v, %v", level.String(), got, err)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("ParseLevel(verbose) expected an error")
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+var (
+	eventsEmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_events_emitted_total",
+		Help: "Events emitted to the event bus by topic and result",
+	}, []string{"topic", "result"})
+
+	eventsConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_events_consumed_total",
+		Help: "Events handled from the event bus by topic and result",
+	}, []string{"topic", "result"})
+
+	eventDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "itembackend_event_handler_duration_seconds",
+		Help:    "Duration of the event handlers by topic",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"topic"})
+)
+
+func init() {
+	prometheus.MustRegister(eventsEmitted, eventsConsumed, eventDuration)
+}
+
+func result(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultOK
+}
+
+// EventBus counts the events emitted through the wrapped event bus
+type EventBus struct {
+	eventbus.EventBus
+}
+
+func NewEventBus(bus eventbus.EventBus) *EventBus {
+	return &EventBus{EventBus: bus}
+}
+
+func (b *EventBus) Emit(topic string, data interface{}) error {
+	err := b.EventBus.Emit(topic, data)
+	eventsEmitted.WithLabelValues(topic, result(err)).Inc()
+	return err
+}
+
+// Consume counts the events handler handles for topic and measures how long it takes
+func Consume(topic string, handler func(msg []byte) error) func(msg []byte) error {
+	return func(msg []byte) error {
+		start := time.Now()
+		err := handler(msg)
+		eventDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+		eventsConsumed.WithLabelValues(topic, result(err)).Inc()
+		return err
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherOperation is the label of the operations beyond the limit of a GraphQLTracer and of anonymous operations
+const OtherOperation = "other"
+
+var (
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "itembackend_graphql_operation_duration_seconds",
+		Help:    "Duration of the execution of GraphQL operations",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"operation"})
+
+	operationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_graphql_operation_errors_total",
+		Help: "Errors returned by GraphQL operations",
+	}, []string{"operation"})
+
+	fieldDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "itembackend_graphql_field_duration_seconds",
+		Help:    "Duration of the resolvers of GraphQL fields, trivial fields are left out",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"type", "field"})
+
+	fieldErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "itembackend_graphql_field_errors_total",
+		Help: "Errors returned by the resolvers of GraphQL fields",
+	}, []string{"type", "field"})
+)
+
+func init() {
+	prometheus.MustRegister(operationDuration, operationErrors, fieldDuration, fieldErrors)
+}
+
+// GraphQLTracer measures operations by their name and resolvers by their type and field before handing them to Next.
+// Operation names are chosen by the clients, so only the first MaxOperations names get a label of their own.
+type GraphQLTracer struct {
+	Next          trace.Tracer
+	MaxOperations int
+
+	mutex      sync.Mutex
+	operations map[string]bool
+}
+
+func NewGraphQLTracer(next trace.Tracer, maxOperations int) *GraphQLTracer {
+	return &GraphQLTracer{
+		Next:          next,
+		MaxOperations: maxOperations,
+		operations:    make(map[string]bool),
+	}
+}
+
+func (t *GraphQLTracer) operationLabel(operationName string) string {
+	if operationName == "" {
+		return OtherOperation
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.operations[operationName] {
+		return operationName
+	}
+	if len(t.operations) >= t.MaxOperations {
+		return OtherOperation
+	}
+	t.operations[operationName] = true
+	return operationName
+}
+
+func (t *GraphQLTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	operation := t.operationLabel(operationName)
+	start := time.Now()
+	ctx, finish := t.Next.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+
+	return ctx, func(errs []*errors.QueryError) {
+		operationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+		if len(errs) > 0 {
+			operationErrors.WithLabelValues(operation).Add(float64(len(errs)))
+		}
+		finish(errs)
+	}
+}
+
+func (t *GraphQLTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	ctx, finish := t.Next.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	if trivial {
+		return ctx, finish
+	}
+	start := time.Now()
+
+	return ctx, func(err *errors.QueryError) {
+		fieldDuration.WithLabelValues(typeName, fieldName).Observe(time.Since(start).Seconds())
+		if err != nil {
+			fieldErrors.WithLabelValues(typeName, fieldName).Inc()
+		}
+		finish(err)
+	}
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+func TestGraphQLTracer_operationLabel(t *testing.T) {
+	tracer := NewGraphQLTracer(trace.NoopTracer{}, 2)
+
+	tests := []struct {
+		operationName string
+		want          string
+	}{
+		{"", OtherOperation},
+		{"items", "items"},
+		{"item", "item"},
+		{"itemByName", OtherOperation},
+		{"items", "items"},
+	}
+	for _, tt := range tests {
+		if got := tracer.operationLabel(tt.operationName); got != tt.want {
+			t.Errorf("GraphQLTracer.operationLabel(%q) = %v, want %v", tt.operationName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package persistedquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dukfaar/itemBackend/graphqlws"
+	"github.com/dukfaar/itemBackend/logging"
+)
+
+// Handler replaces the hash of a persisted query with its query text before the request reaches the graphql handler
+type Handler struct {
+	Next     http.Handler
+	Registry *Registry
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		h.Next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// a request that can not be decoded is rejected, the graphql handler might still run it without the checks
+	request, err := graphqlws.DecodeObject(body, graphqlws.RequestFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Registry.Resolve(r.Context(), request); err != nil {
+		writeError(w, logging.FromContext(r.Context()), err)
+		return
+	}
+	if body, err = json.Marshal(request); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	h.Next.ServeHTTP(w, r)
+}
+
+// writeError answers with status 200 like the graphql handler, clients read the code from the extensions
+func writeError(w http.ResponseWriter, logger *logging.Logger, err error) {
+	persistedErr, ok := err.(*Error)
+	if !ok {
+		logger.Error("Resolving persisted query failed", logging.FieldError, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []interface{}{map[string]interface{}{
+			"message":    persistedErr.Message,
+			"extensions": persistedErr.Extensions(),
+		}},
+	})
+}
@@ -0,0 +1,51 @@
+package persistedquery
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	allowPermissions(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantNext   bool
+	}{
+		{"persisted", `{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + Hash("{ items { totalCount } }") + `"}}}`, http.StatusOK, true},
+		{"adhoc", `{"query":"{ hello }"}`, http.StatusOK, false},
+		{"trailing data", `{"query":"{ hello }"} x`, http.StatusBadRequest, false},
+		{"query in another case", `{"Query":"{ hello }"}`, http.StatusBadRequest, false},
+		{"not an object", `null`, http.StatusBadRequest, false},
+		{"empty", ``, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(&memoryService{queries: map[string]string{
+				Hash("{ items { totalCount } }"): "{ items { totalCount } }",
+			}}, ModeStrict, time.Minute)
+
+			var nextBody string
+			handler := &Handler{Registry: registry, Next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				nextBody = string(body)
+			})}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/graphql", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %v, want %v", recorder.Code, tt.wantStatus)
+			}
+			if ran := nextBody != ""; ran != tt.wantNext {
+				t.Errorf("ServeHTTP() passed on %q, want passed on %v", nextBody, tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package persistedquery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// Model is a registered query, clients send its hash instead of the query text
+type Model struct {
+	ID        bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Hash      string        `json:"hash" bson:"hash"`
+	Query     string        `json:"query" bson:"query"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+}
+
+// Hash is the hex encoded sha256 of query, as sent by automatic persisted query clients
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
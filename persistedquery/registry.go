@@ -0,0 +1,138 @@
+package persistedquery
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dukfaar/goUtils/permission"
+	"github.com/dukfaar/itemBackend/reference"
+	"github.com/globalsign/mgo"
+)
+
+const (
+	ModeAutomatic = "apq"
+	ModeStrict    = "strict"
+)
+
+// AdhocPermission allows sending and registering query text in strict mode
+const AdhocPermission = "graphql.adhocQuery"
+
+const (
+	CodeNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	CodeNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+	CodeHashMismatch = "PERSISTED_QUERY_HASH_MISMATCH"
+	CodeRequired     = "PERSISTED_QUERY_REQUIRED"
+)
+
+var checkPermission = permission.Check
+
+// Error is a persisted query that can not be run, automatic persisted query clients look for the PersistedQueryNotFound
+// message to send the query text along with the hash
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+type persistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+type requestExtensions struct {
+	PersistedQuery *persistedQueryExtension `json:"persistedQuery"`
+}
+
+// Registry resolves the hashes of persisted queries to their query text and registers the queries of
+// automatic persisted query requests. In strict mode only callers with AdhocPermission may send query text.
+type Registry struct {
+	Service Service
+	Strict  bool
+	cache   *reference.Cache
+}
+
+func NewRegistry(service Service, mode string, cacheTTL time.Duration) *Registry {
+	return &Registry{
+		Service: service,
+		Strict:  mode == ModeStrict,
+		cache:   reference.NewCache(cacheTTL),
+	}
+}
+
+func (r *Registry) checkAdhoc(ctx context.Context) error {
+	if !r.Strict {
+		return nil
+	}
+	if err := checkPermission(ctx, AdhocPermission); err != nil {
+		return &Error{Code: CodeRequired, Message: "Only persisted queries are allowed"}
+	}
+	return nil
+}
+
+func (r *Registry) lookup(hash string) (string, error) {
+	return r.cache.Get(hash, func() (string, error) {
+		model, err := r.Service.FindByHash(hash)
+		if err == mgo.ErrNotFound {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return model.Query, nil
+	})
+}
+
+// Resolve fills in the query of a graphql request given as its raw fields.
+// Requests without a persisted query extension are ad-hoc queries and passed on unchanged.
+func (r *Registry) Resolve(ctx context.Context, request map[string]json.RawMessage) error {
+	var query string
+	if raw, ok := request["query"]; ok {
+		json.Unmarshal(raw, &query)
+	}
+
+	var extensions requestExtensions
+	if raw, ok := request["extensions"]; ok {
+		json.Unmarshal(raw, &extensions)
+	}
+
+	persisted := extensions.PersistedQuery
+	if persisted == nil {
+		return r.checkAdhoc(ctx)
+	}
+	if persisted.Version != 1 {
+		return &Error{Code: CodeNotSupported, Message: "PersistedQueryNotSupported"}
+	}
+
+	if query != "" {
+		if Hash(query) != persisted.Sha256Hash {
+			return &Error{Code: CodeHashMismatch, Message: "Provided sha256Hash does not match the query"}
+		}
+		if err := r.checkAdhoc(ctx); err != nil {
+			return err
+		}
+		if _, err := r.Service.Register(query); err != nil {
+			return err
+		}
+		r.cache.Set(persisted.Sha256Hash, query)
+		return nil
+	}
+
+	query, err := r.lookup(persisted.Sha256Hash)
+	if err != nil {
+		return err
+	}
+	if query == "" {
+		return &Error{Code: CodeNotFound, Message: "PersistedQueryNotFound"}
+	}
+
+	request["query"], err = json.Marshal(query)
+	return err
+}
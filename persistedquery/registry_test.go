@@ -0,0 +1,126 @@
+package persistedquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo"
+)
+
+type memoryService struct {
+	Service
+	queries map[string]string
+}
+
+func (s *memoryService) FindByHash(hash string) (*Model, error) {
+	query, ok := s.queries[hash]
+	if !ok {
+		return nil, mgo.ErrNotFound
+	}
+	return &Model{Hash: hash, Query: query}, nil
+}
+
+func (s *memoryService) Register(query string) (*Model, error) {
+	s.queries[Hash(query)] = query
+	return &Model{Hash: Hash(query), Query: query}, nil
+}
+
+func allowPermissions(t *testing.T, allowed ...string) {
+	original := checkPermission
+	checkPermission = func(ctx context.Context, name string) error {
+		for _, permission := range allowed {
+			if permission == name {
+				return nil
+			}
+		}
+		return errors.New("permission denied")
+	}
+	t.Cleanup(func() { checkPermission = original })
+}
+
+func persistedRequest(query string, hash string) map[string]json.RawMessage {
+	request := map[string]json.RawMessage{
+		"extensions": json.RawMessage(`{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`),
+	}
+	if query != "" {
+		request["query"], _ = json.Marshal(query)
+	}
+	return request
+}
+
+func errorCode(err error) string {
+	if persistedErr, ok := err.(*Error); ok {
+		return persistedErr.Code
+	}
+	return ""
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	const query = "{ items { totalCount } }"
+	hash := Hash(query)
+
+	tests := []struct {
+		name      string
+		mode      string
+		allowed   []string
+		known     bool
+		request   map[string]json.RawMessage
+		wantCode  string
+		wantQuery string
+	}{
+		{"known hash", ModeAutomatic, nil, true, persistedRequest("", hash), "", query},
+		{"unknown hash", ModeAutomatic, nil, false, persistedRequest("", hash), CodeNotFound, ""},
+		{"register", ModeAutomatic, nil, false, persistedRequest(query, hash), "", query},
+		{"hash mismatch", ModeAutomatic, nil, false, persistedRequest(query, Hash("{ other }")), CodeHashMismatch, ""},
+		{"adhoc", ModeAutomatic, nil, false, map[string]json.RawMessage{"query": json.RawMessage(`"{ other }"`)}, "", "{ other }"},
+		{"strict known hash", ModeStrict, nil, true, persistedRequest("", hash), "", query},
+		{"strict register", ModeStrict, nil, false, persistedRequest(query, hash), CodeRequired, ""},
+		{"strict adhoc", ModeStrict, nil, false, map[string]json.RawMessage{"query": json.RawMessage(`"{ other }"`)}, CodeRequired, ""},
+		{"strict adhoc admin", ModeStrict, []string{AdhocPermission}, false, map[string]json.RawMessage{"query": json.RawMessage(`"{ other }"`)}, "", "{ other }"},
+		{"strict register admin", ModeStrict, []string{AdhocPermission}, false, persistedRequest(query, hash), "", query},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowPermissions(t, tt.allowed...)
+
+			service := &memoryService{queries: map[string]string{}}
+			if tt.known {
+				service.queries[hash] = query
+			}
+			registry := NewRegistry(service, tt.mode, time.Minute)
+
+			err := registry.Resolve(context.Background(), tt.request)
+			if got := errorCode(err); got != tt.wantCode || (tt.wantCode == "" && err != nil) {
+				t.Fatalf("Resolve() error = %v, want code %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+
+			var got string
+			json.Unmarshal(tt.request["query"], &got)
+			if got != tt.wantQuery {
+				t.Errorf("Resolve() query = %q, want %q", got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestRegistry_ResolveRegistered(t *testing.T) {
+	const query = "{ items { totalCount } }"
+	registry := NewRegistry(&memoryService{queries: map[string]string{}}, ModeAutomatic, time.Minute)
+
+	if err := registry.Resolve(context.Background(), persistedRequest("", Hash(query))); errorCode(err) != CodeNotFound {
+		t.Fatalf("Resolve() error = %v, want %v", err, CodeNotFound)
+	}
+	if err := registry.Resolve(context.Background(), persistedRequest(query, Hash(query))); err != nil {
+		t.Fatalf("Resolve() registering error = %v", err)
+	}
+	if err := registry.Resolve(context.Background(), persistedRequest("", Hash(query))); err != nil {
+		t.Errorf("Resolve() error = %v after registering the query", err)
+	}
+}
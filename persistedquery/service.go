@@ -0,0 +1,60 @@
+package persistedquery
+
+import (
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type Service interface {
+	FindByHash(hash string) (*Model, error)
+	Register(query string) (*Model, error)
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("persistedQueries"),
+	}
+}
+
+// EnsureIndexes makes sure a query is registered once, however many clients register it at the same time
+func (s *MgoService) EnsureIndexes() error {
+	return s.collection.EnsureIndex(mgo.Index{
+		Key:        []string{"hash"},
+		Unique:     true,
+		Background: true,
+	})
+}
+
+func (s *MgoService) FindByHash(hash string) (*Model, error) {
+	var result Model
+
+	err := s.collection.Find(bson.M{"hash": hash}).One(&result)
+
+	return &result, err
+}
+
+// Register stores query under its hash, registering a known query again leaves it untouched
+func (s *MgoService) Register(query string) (*Model, error) {
+	hash := Hash(query)
+
+	_, err := s.collection.Upsert(bson.M{"hash": hash}, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":       bson.NewObjectId(),
+			"query":     query,
+			"createdAt": time.Now(),
+		},
+	})
+	if err != nil && !mgo.IsDup(err) {
+		return nil, err
+	}
+
+	return s.FindByHash(hash)
+}
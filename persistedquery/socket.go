@@ -0,0 +1,12 @@
+package persistedquery
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ResolveOperation resolves the persisted query in the payload of an operation started over a graphql socket,
+// like the body of a graphql request. It is a graphqlws.Hook, an error only fails the operation.
+func (r *Registry) ResolveOperation(request *http.Request, payload map[string]json.RawMessage) error {
+	return r.Resolve(request.Context(), payload)
+}
@@ -0,0 +1,29 @@
+package persistedquery
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_ResolveOperation(t *testing.T) {
+	const query = "{ items { totalCount } }"
+	registry := NewRegistry(&memoryService{queries: map[string]string{Hash(query): query}}, ModeAutomatic, time.Minute)
+	request := httptest.NewRequest("GET", "/socket", nil)
+
+	payload := persistedRequest("", Hash(query))
+	if err := registry.ResolveOperation(request, payload); err != nil {
+		t.Fatalf("ResolveOperation() error = %v", err)
+	}
+	var got string
+	json.Unmarshal(payload["query"], &got)
+	if got != query {
+		t.Errorf("ResolveOperation() query = %q, want %q", got, query)
+	}
+
+	err := registry.ResolveOperation(request, persistedRequest("", Hash("{ unknown }")))
+	if errorCode(err) != CodeNotFound {
+		t.Errorf("ResolveOperation() error = %v, want %v so the client sends the query along", err, CodeNotFound)
+	}
+}
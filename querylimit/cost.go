@@ -0,0 +1,198 @@
+package querylimit
+
+import (
+	"errors"
+	"fmt"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// FieldType is the named type of a field and whether the field returns a list of it
+type FieldType struct {
+	Type string
+	List bool
+}
+
+// CostModel scores queries before they are executed. Every field costs 1 plus the cost of its type,
+// the fields below a list are counted once per element, with first or last as the size of the page.
+type CostModel struct {
+	RootTypes map[string]string
+	Fields    map[string]map[string]FieldType
+	// TypeCosts adds the work of resolving a value of a type, e.g. the count and paging queries behind an ItemConnection
+	TypeCosts map[string]int
+	// DefaultPageSize is assumed for lists that are not limited by first or last
+	DefaultPageSize int
+}
+
+func unwrapType(t *introspection.Type) FieldType {
+	result := FieldType{}
+	for t != nil {
+		switch t.Kind() {
+		case "NON_NULL":
+			t = t.OfType()
+		case "LIST":
+			result.List = true
+			t = t.OfType()
+		default:
+			if t.Name() != nil {
+				result.Type = *t.Name()
+			}
+			return result
+		}
+	}
+	return result
+}
+
+func typeName(t *introspection.Type) string {
+	if t == nil || t.Name() == nil {
+		return ""
+	}
+	return *t.Name()
+}
+
+// NewCostModel reads the field types of every object type of the schema
+func NewCostModel(schema *graphql.Schema, typeCosts map[string]int, defaultPageSize int) *CostModel {
+	inspected := schema.Inspect()
+
+	result := &CostModel{
+		RootTypes: map[string]string{
+			"query":        typeName(inspected.QueryType()),
+			"mutation":     typeName(inspected.MutationType()),
+			"subscription": typeName(inspected.SubscriptionType()),
+		},
+		Fields:          map[string]map[string]FieldType{},
+		TypeCosts:       typeCosts,
+		DefaultPageSize: defaultPageSize,
+	}
+
+	for _, t := range inspected.Types() {
+		fields := t.Fields(&struct{ IncludeDeprecated bool }{true})
+		if fields == nil || t.Name() == nil {
+			continue
+		}
+
+		typeFields := map[string]FieldType{}
+		for _, field := range *fields {
+			typeFields[field.Name()] = unwrapType(field.Type())
+		}
+		result.Fields[*t.Name()] = typeFields
+	}
+
+	return result
+}
+
+// Cost scores the named operation of a query, or its first operation if operationName is empty
+func (m *CostModel) Cost(query string, operationName string, variables map[string]interface{}) (int, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return 0, err
+	}
+
+	var op *operation
+	for i := range doc.operations {
+		if operationName == "" || doc.operations[i].name == operationName {
+			op = &doc.operations[i]
+			break
+		}
+	}
+	if op == nil {
+		return 0, fmt.Errorf("Unknown operation %q", operationName)
+	}
+
+	walker := &costWalker{model: m, document: doc, variables: variables, visiting: map[string]bool{}}
+	return walker.cost(op.selections, m.RootTypes[op.kind], 0)
+}
+
+type costWalker struct {
+	model     *CostModel
+	document  *document
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// pageSize reads first or last of a field, resolving variables
+func (w *costWalker) pageSize(arguments map[string]interface{}) int {
+	result := 0
+	for _, name := range []string{"first", "last"} {
+		value := arguments[name]
+		if ref, ok := value.(variable); ok {
+			value = w.variables[string(ref)]
+		}
+
+		switch size := value.(type) {
+		case int64:
+			if int(size) > result {
+				result = int(size)
+			}
+		case float64:
+			if int(size) > result {
+				result = int(size)
+			}
+		}
+	}
+	return result
+}
+
+func (w *costWalker) cost(selections []selection, parentType string, pageSize int) (int, error) {
+	total := 0
+
+	for _, current := range selections {
+		if current.fragment != "" || current.inline {
+			selectionType, selectionSet := current.typeCondition, current.selections
+			if current.fragment != "" {
+				definition, ok := w.document.fragments[current.fragment]
+				if !ok {
+					return 0, fmt.Errorf("Unknown fragment %q", current.fragment)
+				}
+				if w.visiting[current.fragment] {
+					return 0, errors.New("Fragment cycle on " + current.fragment)
+				}
+				selectionType, selectionSet = definition.typeCondition, definition.selections
+			}
+			if selectionType == "" {
+				selectionType = parentType
+			}
+
+			if current.fragment != "" {
+				w.visiting[current.fragment] = true
+			}
+			cost, err := w.cost(selectionSet, selectionType, pageSize)
+			delete(w.visiting, current.fragment)
+			if err != nil {
+				return 0, err
+			}
+			total += cost
+			continue
+		}
+
+		fieldType := w.model.Fields[parentType][current.name]
+
+		ownPageSize := w.pageSize(current.arguments)
+
+		// a list is sized by its own first or last, the edges of a connection by those of the connection
+		elements := 1
+		if fieldType.List {
+			elements = ownPageSize
+			if elements <= 0 {
+				elements = pageSize
+			}
+			if elements <= 0 {
+				elements = w.model.DefaultPageSize
+			}
+		}
+
+		fieldCost := 1 + w.model.TypeCosts[fieldType.Type]
+		if len(current.selections) > 0 {
+			childCost, err := w.cost(current.selections, fieldType.Type, ownPageSize)
+			if err != nil {
+				return 0, err
+			}
+			fieldCost += childCost
+		}
+
+		total += elements * fieldCost
+	}
+
+	return total, nil
+}
@@ -0,0 +1,98 @@
+package querylimit
+
+import (
+	"testing"
+)
+
+func testCostModel() *CostModel {
+	return &CostModel{
+		RootTypes: map[string]string{"query": "Query", "mutation": "Mutation"},
+		Fields: map[string]map[string]FieldType{
+			"Query": {
+				"items": {Type: "ItemConnection"},
+				"item":  {Type: "Item"},
+			},
+			"Mutation": {
+				"deleteItem": {Type: "ID"},
+			},
+			"ItemConnection": {
+				"totalCount": {Type: "Int"},
+				"edges":      {Type: "ItemEdge", List: true},
+				"pageInfo":   {Type: "PageInfo"},
+			},
+			"ItemEdge": {
+				"node":   {Type: "Item"},
+				"cursor": {Type: "String"},
+			},
+			"PageInfo": {
+				"hasNextPage": {Type: "Boolean"},
+			},
+			"Item": {
+				"id":   {Type: "ID"},
+				"name": {Type: "String"},
+			},
+		},
+		TypeCosts:       map[string]int{"ItemConnection": 3, "Item": 1},
+		DefaultPageSize: 100,
+	}
+}
+
+func TestCostModel_Cost(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]interface{}
+		want          int
+	}{
+		{"single item", `{ item(id: "1") { id name } }`, "", nil, 4},
+		{"page", `{ items(first: 10) { totalCount edges { node { name } } } }`, "", nil, 45},
+		{"last", `{ items(last: 10) { totalCount edges { node { name } } } }`, "", nil, 45},
+		{"default page size", `{ items { totalCount edges { node { name } } } }`, "", nil, 405},
+		{"aliases", `{
+			a: items(first: 10) { totalCount edges { node { name } } }
+			b: items(first: 10) { totalCount edges { node { name } } }
+		}`, "", nil, 90},
+		{"fragment and variable", `query Items($n: Int) { items(first: $n) { ...connection } }
+			fragment connection on ItemConnection { totalCount edges { node { name } } }`,
+			"", map[string]interface{}{"n": float64(10)}, 45},
+		{"inline fragment", `{ items(first: 10) { ... on ItemConnection { totalCount edges { node { name } } } } }`, "", nil, 45},
+		{"named operation", `query A { item(id: "1") { id } } query B { items(first: 1) { totalCount } }`, "B", nil, 5},
+		{"mutation", `mutation { deleteItem(id: "1") }`, "", nil, 1},
+		{"byte order mark", "\uFEFF{ items(first: 10) { totalCount edges { node { name } } } }", "", nil, 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testCostModel().Cost(tt.query, tt.operationName, tt.variables)
+			if err != nil {
+				t.Fatalf("Cost() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostModel_CostErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+	}{
+		{"syntax", `{ items(first: 10) { totalCount }`, ""},
+		{"byte order mark inside", "{ items\uFEFF(first: 10) { totalCount } }", ""},
+		{"unknown operation", `query A { item(id: "1") { id } }`, "B"},
+		{"unknown fragment", `{ items { ...connection } }`, ""},
+		{"fragment cycle", `{ items { ...a } } fragment a on ItemConnection { ...b } fragment b on ItemConnection { ...a }`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := testCostModel().Cost(tt.query, tt.operationName, nil); err == nil {
+				t.Errorf("Cost() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package querylimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeComplexityExceeded = "COMPLEXITY_EXCEEDED"
+	CodePageSizeExceeded   = "PAGE_SIZE_EXCEEDED"
+	CodeInvalidQuery       = "INVALID_QUERY"
+)
+
+var rejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "itembackend_query_limit_rejections_total",
+	Help: "GraphQL requests rejected by the rate limit, the complexity limit, the page size limit or because they can not be scored",
+}, []string{"code"})
+
+func init() {
+	prometheus.MustRegister(rejections)
+}
+
+// Error is a rejected request, its extensions carry the code and, for rate limits, when to retry
+type Error struct {
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+	Cost       int
+	MaxCost    int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	result := map[string]interface{}{"code": e.Code}
+	if e.RetryAfter > 0 {
+		result["retryAfter"] = retryAfterSeconds(e.RetryAfter)
+	}
+	if e.MaxCost > 0 {
+		result["cost"] = e.Cost
+		result["maxCost"] = e.MaxCost
+	}
+	return result
+}
+
+func retryAfterSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+func reject(err *Error) *Error {
+	rejections.WithLabelValues(err.Code).Inc()
+	return err
+}
+
+// CheckPageSize rejects a page larger than max
+func CheckPageSize(first *int32, last *int32, max int32) error {
+	for _, size := range []*int32{first, last} {
+		if size != nil && max > 0 && *size > max {
+			return reject(&Error{
+				Code:    CodePageSizeExceeded,
+				Message: fmt.Sprintf("A page can hold at most %v items, %v were requested", max, *size),
+			})
+		}
+	}
+	return nil
+}
+
+// Handler rate limits the requests of every caller and rejects queries scoring above MaxComplexity.
+// Without a CostModel only the rate is limited, e.g. for socket upgrades.
+type Handler struct {
+	Next          http.Handler
+	Limiter       *Limiter
+	Costs         *CostModel
+	MaxComplexity int
+}
+
+type requestParams struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// allow takes a token from the bucket of the caller
+func (h *Handler) allow(r *http.Request) *Error {
+	if allowed, retryAfter := h.Limiter.Allow(Identity(r), time.Now()); !allowed {
+		return reject(&Error{
+			Code:       CodeRateLimited,
+			Message:    "Too many requests, try again later",
+			RetryAfter: retryAfter,
+		})
+	}
+	return nil
+}
+
+// decodeParams reads a graphql request, anything the graphql handler could read differently is rejected
+func decodeParams(data []byte) (requestParams, *Error) {
+	var result requestParams
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, reject(&Error{Code: CodeInvalidQuery, Message: "Malformed request: " + err.Error()})
+	}
+	return result, nil
+}
+
+// checkComplexity scores the query. A query that can not be scored is rejected, the graphql handler might still run it.
+func (h *Handler) checkComplexity(params requestParams) *Error {
+	if h.Costs == nil || params.Query == "" {
+		return nil
+	}
+
+	cost, err := h.Costs.Cost(params.Query, params.OperationName, params.Variables)
+	if err != nil {
+		return reject(&Error{Code: CodeInvalidQuery, Message: "Query can not be scored: " + err.Error()})
+	}
+	if cost > h.MaxComplexity {
+		return reject(&Error{
+			Code:    CodeComplexityExceeded,
+			Message: fmt.Sprintf("Query is too complex, it scores %v of at most %v", cost, h.MaxComplexity),
+			Cost:    cost,
+			MaxCost: h.MaxComplexity,
+		})
+	}
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.allow(r); err != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(err.RetryAfter)))
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	if h.Costs != nil && r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		params, limitErr := decodeParams(body)
+		if limitErr == nil {
+			limitErr = h.checkComplexity(params)
+		}
+		if limitErr != nil {
+			writeError(w, http.StatusBadRequest, limitErr)
+			return
+		}
+	}
+
+	h.Next.ServeHTTP(w, r)
+}
+
+// Operation charges and scores an operation started over a graphql socket like a request to the graphql endpoint.
+// It is a graphqlws.Hook, a rejection only fails the operation.
+func (h *Handler) Operation(r *http.Request, payload map[string]json.RawMessage) error {
+	if err := h.allow(r); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	params, limitErr := decodeParams(encoded)
+	if limitErr == nil {
+		limitErr = h.checkComplexity(params)
+	}
+	if limitErr != nil {
+		return limitErr
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, err *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []interface{}{map[string]interface{}{
+			"message":    err.Message,
+			"extensions": err.Extensions(),
+		}},
+	})
+}
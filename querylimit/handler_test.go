@@ -0,0 +1,87 @@
+package querylimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"cheap", `{"query":"{ item { id } }"}`, ""},
+		{"too complex", `{"query":"{ items(first: 100) { edges { node { id name } } } }"}`, CodeComplexityExceeded},
+		{"byte order mark", `{"query":"\ufeff{ items(first: 100) { edges { node { id name } } } }"}`, CodeComplexityExceeded},
+		{"trailing data", `{"query":"{ items(first: 100) { edges { node { id name } } } }"} x`, CodeInvalidQuery},
+		{"unparsable", `{"query":"{ items(first: 100) { edges { node { id name } } } } }"}`, CodeInvalidQuery},
+		{"unknown operation", `{"query":"query A { item { id } }","operationName":"B"}`, CodeInvalidQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed := false
+			handler := &Handler{
+				Limiter:       NewLimiter(100, 100),
+				Costs:         testCostModel(),
+				MaxComplexity: 50,
+				Next:          http.HandlerFunc(func(http.ResponseWriter, *http.Request) { passed = true }),
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/graphql", strings.NewReader(tt.body)))
+
+			var response struct {
+				Errors []struct {
+					Extensions map[string]interface{} `json:"extensions"`
+				} `json:"errors"`
+			}
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+
+			code := ""
+			if len(response.Errors) > 0 {
+				code, _ = response.Errors[0].Extensions["code"].(string)
+			}
+			if code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %q, want %q", code, tt.wantCode)
+			}
+			if passed != (tt.wantCode == "") {
+				t.Errorf("ServeHTTP() passed the request on %v, want only unrejected requests passed on", passed)
+			}
+		})
+	}
+}
+
+func TestHandler_Operation(t *testing.T) {
+	handler := &Handler{Limiter: NewLimiter(1, 3), Costs: testCostModel(), MaxComplexity: 50}
+	request := httptest.NewRequest("GET", "/socket", nil)
+
+	tests := []struct {
+		name     string
+		payload  map[string]json.RawMessage
+		wantCode string
+	}{
+		{"cheap", map[string]json.RawMessage{"query": json.RawMessage(`"{ item { id } }"`)}, ""},
+		{"too complex", map[string]json.RawMessage{"query": json.RawMessage(`"{ items(first: 100) { edges { node { id name } } } }"`)}, CodeComplexityExceeded},
+		{"unparsable", map[string]json.RawMessage{"query": json.RawMessage(`"{ items { totalCount }"`)}, CodeInvalidQuery},
+		{"rate limited", map[string]json.RawMessage{"query": json.RawMessage(`"{ item { id } }"`)}, CodeRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handler.Operation(request, tt.payload)
+
+			code := ""
+			if limitErr, ok := err.(*Error); ok {
+				code = limitErr.Code
+			} else if err != nil {
+				t.Fatalf("Operation() error = %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Operation() code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package querylimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter keeps a token bucket per caller, refilled at Rate tokens per second up to Burst tokens
+type Limiter struct {
+	Rate  float64
+	Burst float64
+
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		Rate:    rate,
+		Burst:   float64(burst),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty it tells how long to wait for the next token.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.sweep(now)
+
+	current, ok := l.buckets[key]
+	if !ok {
+		current = &bucket{tokens: l.Burst, updated: now}
+		l.buckets[key] = current
+	}
+
+	current.tokens = math.Min(l.Burst, current.tokens+now.Sub(current.updated).Seconds()*l.Rate)
+	current.updated = now
+
+	if current.tokens >= 1 {
+		current.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - current.tokens) / l.Rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have been refilled completely, they are the same as a new bucket
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.Burst / l.Rate * float64(time.Second))
+	for key, current := range l.buckets {
+		if now.Sub(current.updated) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Identity keys the limits on the access token of the caller, which belongs to a single user or client.
+// Anonymous requests are keyed on their remote address.
+func Identity(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		sum := sha256.Sum256([]byte(authorization))
+		return "token:" + hex.EncodeToString(sum[:12])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "address:" + host
+}
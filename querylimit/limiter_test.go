@@ -0,0 +1,50 @@
+package querylimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := NewLimiter(2, 3)
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow("a", now); !allowed {
+			t.Fatalf("Allow() rejected request %v of the burst", i)
+		}
+	}
+
+	allowed, retryAfter := limiter.Allow("a", now)
+	if allowed {
+		t.Fatalf("Allow() allowed a request beyond the burst")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("Allow() retryAfter = %v, want 500ms", retryAfter)
+	}
+
+	if allowed, _ := limiter.Allow("b", now); !allowed {
+		t.Errorf("Allow() limited another caller")
+	}
+
+	if allowed, _ := limiter.Allow("a", now.Add(retryAfter)); !allowed {
+		t.Errorf("Allow() rejected a request after retryAfter")
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	anonymous := httptest.NewRequest("POST", "/graphql", nil)
+	anonymous.RemoteAddr = "10.0.0.1:1234"
+	if got := Identity(anonymous); got != "address:10.0.0.1" {
+		t.Errorf("Identity() = %q", got)
+	}
+
+	a := httptest.NewRequest("POST", "/graphql", nil)
+	a.Header.Set("Authorization", "Bearer a")
+	b := httptest.NewRequest("POST", "/graphql", nil)
+	b.Header.Set("Authorization", "Bearer b")
+	if Identity(a) == Identity(b) {
+		t.Errorf("Identity() keyed different tokens the same")
+	}
+}
@@ -0,0 +1,437 @@
+package querylimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	tokenEOF = iota
+	tokenName
+	tokenPunctuator
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokenize splits a graphql document into the tokens the cost parser needs, ignoring whitespace, commas and comments
+func tokenize(source string) ([]token, error) {
+	result := make([]token, 0)
+
+	// like the lexer of the graphql handler, a leading byte order mark is discarded
+	source = strings.TrimPrefix(source, "\uFEFF")
+
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			pos++
+		case c == '#':
+			for pos < len(source) && source[pos] != '\n' && source[pos] != '\r' {
+				pos++
+			}
+		case strings.HasPrefix(source[pos:], "..."):
+			result = append(result, token{tokenPunctuator, "..."})
+			pos += 3
+		case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+			result = append(result, token{tokenPunctuator, string(c)})
+			pos++
+		case isNameStart(c):
+			start := pos
+			for pos < len(source) && (isNameStart(source[pos]) || isDigit(source[pos])) {
+				pos++
+			}
+			result = append(result, token{tokenName, source[start:pos]})
+		case c == '-' || isDigit(c):
+			start := pos
+			kind := tokenInt
+			pos++
+			for pos < len(source) && (isDigit(source[pos]) || strings.IndexByte(".eE+-", source[pos]) >= 0) {
+				if !isDigit(source[pos]) {
+					kind = tokenFloat
+				}
+				pos++
+			}
+			result = append(result, token{kind, source[start:pos]})
+		case strings.HasPrefix(source[pos:], `"""`):
+			end := strings.Index(source[pos+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated block string at %v", pos)
+			}
+			result = append(result, token{tokenString, source[pos+3 : pos+3+end]})
+			pos += end + 6
+		case c == '"':
+			end := pos + 1
+			for end < len(source) && source[end] != '"' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("Unterminated string at %v", pos)
+			}
+			value, err := strconv.Unquote(source[pos : end+1])
+			if err != nil {
+				value = source[pos+1 : end]
+			}
+			result = append(result, token{tokenString, value})
+			pos = end + 1
+		default:
+			return nil, fmt.Errorf("Unexpected character %q at %v", c, pos)
+		}
+	}
+
+	return append(result, token{kind: tokenEOF}), nil
+}
+
+// variable is an argument value that refers to a query variable
+type variable string
+
+type selection struct {
+	name          string
+	arguments     map[string]interface{}
+	selections    []selection
+	fragment      string
+	typeCondition string
+	inline        bool
+}
+
+type operation struct {
+	kind       string
+	name       string
+	selections []selection
+}
+
+type fragment struct {
+	typeCondition string
+	selections    []selection
+}
+
+type document struct {
+	operations []operation
+	fragments  map[string]fragment
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	result := p.tokens[p.pos]
+	if result.kind != tokenEOF {
+		p.pos++
+	}
+	return result
+}
+
+func (p *parser) is(value string) bool {
+	current := p.peek()
+	return current.kind != tokenString && current.value == value
+}
+
+func (p *parser) skip(value string) bool {
+	if p.is(value) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if !p.skip(value) {
+		return fmt.Errorf("Expected %q, found %q", value, p.peek().value)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	current := p.next()
+	if current.kind != tokenName {
+		return "", fmt.Errorf("Expected a name, found %q", current.value)
+	}
+	return current.value, nil
+}
+
+func parseDocument(source string) (*document, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	result := &document{fragments: map[string]fragment{}}
+
+	for p.peek().kind != tokenEOF {
+		if p.is("{") {
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			result.operations = append(result.operations, operation{kind: "query", selections: selections})
+			continue
+		}
+
+		kind, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "query", "mutation", "subscription":
+			op, err := p.operation(kind)
+			if err != nil {
+				return nil, err
+			}
+			result.operations = append(result.operations, op)
+		case "fragment":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			fragmentDefinition, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			result.fragments[name] = fragmentDefinition
+		default:
+			return nil, fmt.Errorf("Unexpected definition %q", kind)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *parser) operation(kind string) (operation, error) {
+	result := operation{kind: kind}
+
+	if p.peek().kind == tokenName {
+		result.name = p.next().value
+	}
+
+	if p.skip("(") {
+		for !p.skip(")") {
+			if p.peek().kind == tokenEOF {
+				return result, fmt.Errorf("Unterminated variable definitions")
+			}
+			p.next()
+		}
+	}
+
+	if err := p.directives(); err != nil {
+		return result, err
+	}
+
+	selections, err := p.selectionSet()
+	result.selections = selections
+	return result, err
+}
+
+func (p *parser) fragment() (fragment, error) {
+	result := fragment{}
+
+	if on, err := p.name(); err != nil || on != "on" {
+		return result, fmt.Errorf("Expected a type condition")
+	}
+
+	typeCondition, err := p.name()
+	if err != nil {
+		return result, err
+	}
+	result.typeCondition = typeCondition
+
+	if err := p.directives(); err != nil {
+		return result, err
+	}
+
+	result.selections, err = p.selectionSet()
+	return result, err
+}
+
+func (p *parser) directives() error {
+	for p.skip("@") {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.is("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	result := make([]selection, 0)
+	for !p.skip("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, fmt.Errorf("Unterminated selection set")
+		}
+
+		current, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, current)
+	}
+
+	return result, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	result := selection{}
+
+	if p.skip("...") {
+		if p.peek().kind == tokenName && p.peek().value != "on" {
+			result.fragment = p.next().value
+			return result, p.directives()
+		}
+
+		result.inline = true
+		if p.skip("on") {
+			typeCondition, err := p.name()
+			if err != nil {
+				return result, err
+			}
+			result.typeCondition = typeCondition
+		}
+		if err := p.directives(); err != nil {
+			return result, err
+		}
+
+		var err error
+		result.selections, err = p.selectionSet()
+		return result, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return result, err
+	}
+	if p.skip(":") {
+		if name, err = p.name(); err != nil {
+			return result, err
+		}
+	}
+	result.name = name
+
+	if p.is("(") {
+		if result.arguments, err = p.arguments(); err != nil {
+			return result, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return result, err
+	}
+	if p.is("{") {
+		result.selections, err = p.selectionSet()
+	}
+
+	return result, err
+}
+
+func (p *parser) arguments() (map[string]interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	for !p.skip(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if result[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (p *parser) value() (interface{}, error) {
+	current := p.next()
+
+	switch current.kind {
+	case tokenInt:
+		return strconv.ParseInt(current.value, 10, 64)
+	case tokenFloat:
+		return strconv.ParseFloat(current.value, 64)
+	case tokenString:
+		return current.value, nil
+	case tokenName:
+		switch current.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return current.value, nil
+	}
+
+	switch current.value {
+	case "$":
+		name, err := p.name()
+		return variable(name), err
+	case "[":
+		result := make([]interface{}, 0)
+		for !p.skip("]") {
+			if p.peek().kind == tokenEOF {
+				return nil, fmt.Errorf("Unterminated list")
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	case "{":
+		result := map[string]interface{}{}
+		for !p.skip("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if result[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("Unexpected %q", current.value)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultRCItemURL  = "https://rc.dukfaar.com/api/item"
+	rcImportPageSize  = 500
+	rcImportBatchSize = 50
+)
+
+// RCItemClient streams the item list of the RC api page by page, so a large catalog is never held in memory as a whole
+type RCItemClient struct {
+	URL        string
+	HTTPClient *http.Client
+	PageSize   int
+	BatchSize  int
+}
+
+// RCItemBatchHandler receives the items in batches, together with the number of items read so far
+// and the total announced by the api (0 while it is not known yet)
+type RCItemBatchHandler func(batch []map[string]interface{}, read int, total int) error
+
+func NewRCItemClient(url string) *RCItemClient {
+	return &RCItemClient{
+		URL:        url,
+		HTTPClient: http.DefaultClient,
+		PageSize:   rcImportPageSize,
+		BatchSize:  rcImportBatchSize,
+	}
+}
+
+func (c *RCItemClient) pageURL(skip int) (string, error) {
+	pageURL, err := url.Parse(c.URL)
+	if err != nil {
+		return "", err
+	}
+
+	query := pageURL.Query()
+	query.Set("skip", strconv.Itoa(skip))
+	query.Set("limit", strconv.Itoa(c.PageSize))
+	pageURL.RawQuery = query.Encode()
+
+	return pageURL.String(), nil
+}
+
+// Stream requests pages until the announced count is reached or a page comes back short.
+// It stops with the error of ctx as soon as ctx is done, or with the first error of handler.
+func (c *RCItemClient) Stream(ctx context.Context, handler RCItemBatchHandler) error {
+	read, total := 0, 0
+
+	for {
+		pageRead, pageTotal, err := c.streamPage(ctx, read, total, handler)
+		if err != nil {
+			return err
+		}
+
+		read += pageRead
+		if pageTotal > 0 {
+			total = pageTotal
+		}
+
+		if pageRead == 0 || pageRead < c.PageSize || (total > 0 && read >= total) {
+			return nil
+		}
+	}
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("Expected %v in RC response, got %v", delim, token)
+	}
+
+	return nil
+}
+
+func (c *RCItemClient) streamPage(ctx context.Context, skip int, total int, handler RCItemBatchHandler) (int, int, error) {
+	pageURL, err := c.pageURL(skip)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	request, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	response, err := c.HTTPClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("RC answered with %v", response.Status)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return 0, 0, err
+	}
+
+	read := 0
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return read, total, err
+		}
+
+		switch token {
+		case "count":
+			err = decoder.Decode(&total)
+		case "list":
+			read, err = c.streamList(ctx, decoder, skip, &total, handler)
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+		}
+
+		if err != nil {
+			return read, total, err
+		}
+	}
+
+	return read, total, nil
+}
+
+func (c *RCItemClient) streamList(ctx context.Context, decoder *json.Decoder, skip int, total *int, handler RCItemBatchHandler) (int, error) {
+	if err := expectDelim(decoder, '['); err != nil {
+		return 0, err
+	}
+
+	read := 0
+	batch := make([]map[string]interface{}, 0, c.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := handler(batch, skip+read, *total)
+		batch = make([]map[string]interface{}, 0, c.BatchSize)
+		return err
+	}
+
+	for decoder.More() {
+		if err := ctx.Err(); err != nil {
+			return read, err
+		}
+
+		var itemData map[string]interface{}
+		if err := decoder.Decode(&itemData); err != nil {
+			return read, err
+		}
+
+		batch = append(batch, itemData)
+		read++
+
+		if len(batch) >= c.BatchSize {
+			if err := flush(); err != nil {
+				return read, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return read, err
+	}
+
+	return read, expectDelim(decoder, ']')
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newRCItemServer(count int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		list := make([]map[string]interface{}, 0)
+		for i := skip; i < count && i < skip+limit; i++ {
+			list = append(list, map[string]interface{}{"name": fmt.Sprintf("item %v", i)})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"list":  list,
+			"count": count,
+		})
+	}))
+}
+
+func TestRCItemClient_Stream(t *testing.T) {
+	server := newRCItemServer(5)
+	defer server.Close()
+
+	client := NewRCItemClient(server.URL)
+	client.PageSize = 2
+	client.BatchSize = 2
+
+	names := make([]string, 0)
+	lastRead, lastTotal := 0, 0
+	err := client.Stream(context.Background(), func(batch []map[string]interface{}, read int, total int) error {
+		for _, itemData := range batch {
+			names = append(names, itemData["name"].(string))
+		}
+		lastRead, lastTotal = read, total
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if len(names) != 5 || names[0] != "item 0" || names[4] != "item 4" {
+		t.Errorf("Stream() read %v", names)
+	}
+	if lastRead != 5 || lastTotal != 5 {
+		t.Errorf("Stream() progress = %v/%v, want 5/5", lastRead, lastTotal)
+	}
+}
+
+func TestRCItemClient_StreamCancel(t *testing.T) {
+	server := newRCItemServer(10)
+	defer server.Close()
+
+	client := NewRCItemClient(server.URL)
+	client.PageSize = 10
+	client.BatchSize = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := 0
+	err := client.Stream(ctx, func(batch []map[string]interface{}, read int, total int) error {
+		batches++
+		cancel()
+		return nil
+	})
+
+	if err != context.Canceled {
+		t.Errorf("Stream() error = %v, want %v", err, context.Canceled)
+	}
+	if batches != 1 {
+		t.Errorf("Stream() handled %v batches after cancel, want 1", batches)
+	}
+}
@@ -0,0 +1,99 @@
+package reference
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// Cache holds resolved reference ids for a limited time. Concurrent lookups of the same key share a single load,
+// failed or empty loads are not cached. When reloading an expired entry fails, the stale value is returned instead.
+type Cache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	calls   map[string]*cacheCall
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+		calls:   make(map[string]*cacheCall),
+	}
+}
+
+func (c *Cache) Get(key string, load func() (string, error)) (string, error) {
+	c.mutex.Lock()
+
+	entry, cached := c.entries[key]
+	if cached && c.now().Before(entry.expiresAt) {
+		c.mutex.Unlock()
+		return entry.value, nil
+	}
+
+	if call, ok := c.calls[key]; ok {
+		c.mutex.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	call.value, call.err = load()
+	if call.err != nil && cached {
+		call.value, call.err = entry.value, nil
+	}
+
+	c.mutex.Lock()
+	delete(c.calls, key)
+	if call.err == nil && call.value != "" {
+		c.entries[key] = cacheEntry{
+			value:     call.value,
+			expiresAt: c.now().Add(c.ttl),
+		}
+	}
+	c.mutex.Unlock()
+
+	close(call.done)
+
+	return call.value, call.err
+}
+
+func (c *Cache) Set(key string, value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = cacheEntry{
+		value:     value,
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+func (c *Cache) Invalidate(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *Cache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
@@ -0,0 +1,82 @@
+package reference
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_Get(t *testing.T) {
+	cache := NewCache(time.Minute)
+	var loads int32
+
+	release := make(chan struct{})
+	load := func() (string, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "id", nil
+	}
+
+	var wait sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if value, err := cache.Get("key", load); value != "id" || err != nil {
+				t.Errorf("Cache.Get() = %v, %v", value, err)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wait.Wait()
+
+	if loads != 1 {
+		t.Errorf("Cache.Get() loaded %v times, want concurrent lookups to share one load", loads)
+	}
+}
+
+func TestCache_GetDoesNotCacheFailures(t *testing.T) {
+	cache := NewCache(time.Minute)
+
+	cache.Get("failed", func() (string, error) { return "", errors.New("broken") })
+	cache.Get("empty", func() (string, error) { return "", nil })
+
+	for _, key := range []string{"failed", "empty"} {
+		value, _ := cache.Get(key, func() (string, error) { return "id", nil })
+		if value != "id" {
+			t.Errorf("Cache.Get(%v) = %v, want a new load", key, value)
+		}
+	}
+}
+
+func TestCache_GetExpires(t *testing.T) {
+	cache := NewCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Get("key", func() (string, error) { return "old", nil })
+	now = now.Add(2 * time.Minute)
+
+	value, _ := cache.Get("key", func() (string, error) { return "new", nil })
+	if value != "new" {
+		t.Errorf("Cache.Get() = %v, want the expired entry to be reloaded", value)
+	}
+}
+
+func TestCache_GetServesStaleOnFailure(t *testing.T) {
+	cache := NewCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Get("key", func() (string, error) { return "old", nil })
+	now = now.Add(2 * time.Minute)
+
+	value, err := cache.Get("key", func() (string, error) { return "", errors.New("unavailable") })
+	if value != "old" || err != nil {
+		t.Errorf("Cache.Get() = %v, %v, want the stale value", value, err)
+	}
+}
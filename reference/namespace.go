@@ -0,0 +1,85 @@
+package reference
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	dukgraphql "github.com/dukfaar/goUtils/graphql"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/metrics"
+	"github.com/dukfaar/itemBackend/tracing"
+	"github.com/globalsign/mgo/bson"
+)
+
+const namespaceQuery = `query($name: String!) {
+	namespaceByName(name: $name) { _id name }
+}`
+
+type namespaceEventData struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+// NamespaceID resolves a namespace by name
+func (r *Resolver) NamespaceID(name string) (string, error) {
+	return r.namespaces.Get(name, func() (string, error) {
+		span := r.startFetch("namespaceByName")
+		namespaceResult, err := r.fetcher.Fetch(dukgraphql.Request{
+			Query: namespaceQuery,
+			Variables: map[string]interface{}{
+				"name": name,
+			},
+		})
+		tracing.End(span, err)
+
+		if err != nil {
+			r.logger.Error("Fetching namespace failed", "namespace", name, logging.FieldError, err)
+			return "", err
+		}
+
+		namespaceResponse := dukgraphql.Response{namespaceResult}
+
+		return namespaceResponse.GetObject("namespaceByName").GetString("_id"), nil
+	})
+}
+
+// ResolveNamespace accepts either a namespace id or a namespace name and returns the id
+func (r *Resolver) ResolveNamespace(nameOrID string) (string, error) {
+	if bson.IsObjectIdHex(nameOrID) {
+		return nameOrID, nil
+	}
+
+	id, err := r.NamespaceID(nameOrID)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		return "", fmt.Errorf("Unknown namespace: %v", nameOrID)
+	}
+
+	return id, nil
+}
+
+// AddNamespaceEventHandlers keeps the namespace cache in sync with the namespace service
+func AddNamespaceEventHandlers(bus eventbus.EventBus, r *Resolver) {
+	refresh := func(msg []byte) error {
+		var namespace namespaceEventData
+		if err := json.Unmarshal(msg, &namespace); err != nil || namespace.Name == "" {
+			r.namespaces.Clear()
+			return nil
+		}
+
+		r.namespaces.Clear()
+		r.namespaces.Set(namespace.Name, namespace.ID)
+		return nil
+	}
+
+	bus.On("namespace.created", "item", metrics.Consume("namespace.created", refresh))
+	bus.On("namespace.updated", "item", metrics.Consume("namespace.updated", refresh))
+	bus.On("namespace.deleted", "item", metrics.Consume("namespace.deleted", func(msg []byte) error {
+		r.namespaces.Clear()
+		return nil
+	}))
+}
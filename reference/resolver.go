@@ -0,0 +1,80 @@
+package reference
+
+import (
+	"context"
+	"time"
+
+	dukgraphql "github.com/dukfaar/goUtils/graphql"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const classQuery = `query($name: String!, $namespaceId: ID!) {
+	classByNameOrSynonym(name: $name, namespaceId: $namespaceId) { _id name }
+}`
+
+// Resolver looks up the ids of objects owned by other services through the api gateway
+type Resolver struct {
+	fetcher    dukgraphql.Fetcher
+	classes    *Cache
+	namespaces *Cache
+	logger     *logging.Logger
+	ctx        context.Context
+}
+
+func NewResolver(fetcher dukgraphql.Fetcher, ttl time.Duration, logger *logging.Logger) *Resolver {
+	return &Resolver{
+		fetcher:    fetcher,
+		classes:    NewCache(ttl),
+		namespaces: NewCache(ttl),
+		logger:     logger,
+		ctx:        context.Background(),
+	}
+}
+
+// WithContext returns a resolver sharing the caches of r, its lookups are traced as part of the trace of ctx
+func (r *Resolver) WithContext(ctx context.Context) *Resolver {
+	scoped := *r
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// startFetch opens the span of a lookup through the api gateway. The fetcher sends its own requests,
+// so the gateway starts a trace of its own.
+func (r *Resolver) startFetch(field string) trace.Span {
+	_, span := tracing.Start(r.ctx, "apigateway "+field,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("graphql.field.name", field)),
+	)
+	return span
+}
+
+// ClassID resolves a class by name or synonym. An empty name resolves to an empty id without a lookup.
+func (r *Resolver) ClassID(name string, namespaceID string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	return r.classes.Get(namespaceID+"/"+name, func() (string, error) {
+		span := r.startFetch("classByNameOrSynonym")
+		classResult, err := r.fetcher.Fetch(dukgraphql.Request{
+			Query: classQuery,
+			Variables: map[string]interface{}{
+				"name":        name,
+				"namespaceId": namespaceID,
+			},
+		})
+		tracing.End(span, err)
+
+		if err != nil {
+			r.logger.Error("Fetching class failed", "class", name, "namespaceId", namespaceID, logging.FieldError, err)
+			return "", err
+		}
+
+		classResponse := dukgraphql.Response{classResult}
+
+		return classResponse.GetObject("classByNameOrSynonym").GetString("_id"), nil
+	})
+}
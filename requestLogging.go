@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+)
+
+// RequestLogger hands every request a logger carrying its request and user id,
+// the item service of the request logs with it as well
+func RequestLogger(logger *logging.Logger, next http.Handler) http.Handler {
+	return logging.Handler(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if itemService, ok := ctx.Value("itemService").(item.Service); ok {
+			ctx = context.WithValue(ctx, "itemService", item.WithLogger(itemService, logging.FromContext(ctx)))
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
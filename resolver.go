@@ -2,21 +2,32 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dukfaar/goUtils/eventbus"
-	dukgraphql "github.com/dukfaar/goUtils/graphql"
 	"github.com/dukfaar/goUtils/permission"
 	"github.com/dukfaar/goUtils/relay"
+	"github.com/dukfaar/itemBackend/deadletter"
+	"github.com/dukfaar/itemBackend/export"
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/importsource"
 	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/querylimit"
+	"github.com/dukfaar/itemBackend/review"
+	"github.com/dukfaar/itemBackend/schedule"
+	"github.com/dukfaar/itemBackend/snapshot"
 	"github.com/globalsign/mgo/bson"
 	graphql "github.com/graph-gophers/graphql-go"
 )
 
 type Resolver struct {
+	Imports *ImportRunner
+	// MaxPageSize caps first and last of items, it is also the page size if neither is given
+	MaxPageSize int32
 }
 
 func (r *Resolver) Items(ctx context.Context, args struct {
@@ -25,32 +36,53 @@ func (r *Resolver) Items(ctx context.Context, args struct {
 	Before *string
 	After  *string
 	Name   *string
+	Locale *string
 }) (*item.ConnectionResolver, error) {
-	err := permission.Check(ctx, "query.items")
-	if err != nil {
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	if err := querylimit.CheckPageSize(args.First, args.Last, r.MaxPageSize); err != nil {
 		return nil, err
 	}
+	if args.First == nil && args.Last == nil && r.MaxPageSize > 0 {
+		pageSize := r.MaxPageSize
+		args.First = &pageSize
+	}
 
-	itemService := ctx.Value("itemService").(item.Service)
+	namespaceIDs, err := item.ReadableNamespaces(ctx, "query.items", itemService.NamespaceIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	var totalChannel = make(chan int)
-	go func() {
+	makeQuery := func() bson.M {
 		query := itemService.MakeBaseQuery()
 		if args.Name != nil {
 			itemService.MakeNameRegexQuery(query, *args.Name, "i")
 		}
-		var total, _ = itemService.CountWithQuery(query)
+		if namespaceIDs != nil {
+			query["namespaceId"] = bson.M{"$in": namespaceIDs}
+		}
+		return query
+	}
+
+	logger := logging.FromContext(ctx)
+
+	var totalChannel = make(chan int)
+	go func() {
+		var total, err = itemService.CountWithQuery(makeQuery())
+		if err != nil {
+			logger.Error("Counting items failed", logging.FieldError, err)
+		}
 		totalChannel <- total
 	}()
 
 	var itemsChannel = make(chan []item.Model)
 	go func() {
-		query := itemService.MakeBaseQuery()
+		query := makeQuery()
 		itemService.MakeListQuery(query, args.Before, args.After)
-		if args.Name != nil {
-			itemService.MakeNameRegexQuery(query, *args.Name, "i")
+		result, err := itemService.PerformListQuery(query, args.First, args.Last, args.Before, args.After)
+		if err != nil {
+			logger.Error("Listing items failed", logging.FieldError, err)
 		}
-		result, _ := itemService.PerformListQuery(query, args.First, args.Last, args.Before, args.After)
 		itemsChannel <- result
 	}()
 
@@ -67,14 +99,11 @@ func (r *Resolver) Items(ctx context.Context, args struct {
 		start, end = items[0].ID.Hex(), items[len(items)-1].ID.Hex()
 	}
 
-	query := itemService.MakeBaseQuery()
-	if args.Name != nil {
-		itemService.MakeNameRegexQuery(query, *args.Name, "i")
-	}
-	hasPreviousPageChannel, hasNextPageChannel := relay.GetHasPreviousAndNextPageWithQuery(query, len(items), start, end, itemService)
+	hasPreviousPageChannel, hasNextPageChannel := relay.GetHasPreviousAndNextPageWithQuery(makeQuery(), len(items), start, end, itemService)
 
 	return &item.ConnectionResolver{
 		Models: items,
+		Locale: resolveLocale(ctx, args.Locale),
 		ConnectionResolver: relay.ConnectionResolver{
 			relay.Connection{
 				Total:           int32(<-totalChannel),
@@ -87,23 +116,32 @@ func (r *Resolver) Items(ctx context.Context, args struct {
 	}, nil
 }
 
-func (r *Resolver) CreateItem(ctx context.Context, args struct {
-	Name        *string
-	NamespaceId *string
-}) (*item.Resolver, error) {
-	err := permission.Check(ctx, "query.createItem")
+func (r *Resolver) CreateItem(ctx context.Context, args item.Patch) (*item.Resolver, error) {
+	if args.Name == nil || args.NamespaceId == nil || !bson.IsObjectIdHex(*args.NamespaceId) {
+		return nil, errors.New("An item needs a name and a namespace")
+	}
+	namespaceID := bson.ObjectIdHex(*args.NamespaceId)
+
+	err := item.CheckInNamespace(ctx, "query.createItem", namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = item.CheckWrite(ctx, namespaceID, args.Fields())
 	if err != nil {
 		return nil, err
 	}
 
-	itemService := ctx.Value("itemService").(item.Service)
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+	mergePolicy := ctx.Value("mergePolicy").(item.MergePolicy)
 
-	newModel, err := itemService.Create(&item.Model{
-		Name:        *args.Name,
-		NamespaceID: bson.ObjectIdHex(*args.NamespaceId),
-	})
+	newItem := &item.Model{}
+	args.Apply(newItem)
+
+	newModel, err := itemService.Create(mergePolicy.Merge(&item.Model{}, newItem, item.SourceManual, time.Now()))
 
 	if err == nil {
+		logging.FromContext(ctx).Info("Created item", logging.FieldItemID, newModel.ID.Hex())
 		return &item.Resolver{
 			Model: newModel,
 		}, nil
@@ -113,23 +151,92 @@ func (r *Resolver) CreateItem(ctx context.Context, args struct {
 }
 
 func (r *Resolver) UpdateItem(ctx context.Context, args struct {
-	Id          string
-	Name        *string
-	NamespaceId *string
+	Id               string
+	Name             *string
+	NamespaceId      *string
+	Description      *string
+	GatheringLevel   *int32
+	GatheringJobId   *string
+	GatheringEffort  *int32
+	Price            *int32
+	PriceHq          *int32
+	UnspoiledNode    *bool
+	AvailableFromNpc *bool
 }) (*item.Resolver, error) {
-	err := permission.Check(ctx, "query.updateItem")
+	patch := item.Patch{
+		Name:             args.Name,
+		NamespaceId:      args.NamespaceId,
+		Description:      args.Description,
+		GatheringLevel:   args.GatheringLevel,
+		GatheringJobId:   args.GatheringJobId,
+		GatheringEffort:  args.GatheringEffort,
+		Price:            args.Price,
+		PriceHq:          args.PriceHq,
+		UnspoiledNode:    args.UnspoiledNode,
+		AvailableFromNpc: args.AvailableFromNpc,
+	}
+
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+	mergePolicy := ctx.Value("mergePolicy").(item.MergePolicy)
+
+	existingModel, err := itemService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkItemWrite(ctx, "query.updateItem", existingModel.NamespaceID, patch.Fields())
 	if err != nil {
 		return nil, err
 	}
 
-	itemService := ctx.Value("itemService").(item.Service)
+	if patch.NamespaceId != nil {
+		if !bson.IsObjectIdHex(*patch.NamespaceId) {
+			return nil, fmt.Errorf("Invalid namespace id: %v", *patch.NamespaceId)
+		}
 
-	newModel, err := itemService.Update(args.Id, &item.Model{
-		Name:        *args.Name,
-		NamespaceID: bson.ObjectIdHex(*args.NamespaceId),
-	})
+		err = checkItemWrite(ctx, "query.updateItem", bson.ObjectIdHex(*patch.NamespaceId), patch.Fields())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	patchedModel := existingModel.Clone()
+	patch.Apply(patchedModel)
+
+	newModel, err := itemService.Update(args.Id, mergePolicy.Merge(existingModel, patchedModel, item.SourceManual, time.Now()))
+
+	if err == nil {
+		logging.FromContext(ctx).Info("Updated item", logging.FieldItemID, args.Id, "fields", patch.Fields())
+		return &item.Resolver{
+			Model: newModel,
+		}, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) UnlockItemFields(ctx context.Context, args struct {
+	Id     string
+	Fields []string
+}) (*item.Resolver, error) {
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	itemModel, err := itemService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkItemWrite(ctx, "mutation.unlockItemFields", itemModel.NamespaceID, args.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Unlock(itemModel, args.Fields)
+
+	newModel, err := itemService.Update(args.Id, itemModel)
 
 	if err == nil {
+		logging.FromContext(ctx).Info("Unlocked item fields", logging.FieldItemID, args.Id, "fields", args.Fields)
 		return &item.Resolver{
 			Model: newModel,
 		}, nil
@@ -138,20 +245,53 @@ func (r *Resolver) UpdateItem(ctx context.Context, args struct {
 	return nil, err
 }
 
+func (r *Resolver) ItemMergePolicy(ctx context.Context) ([]*item.FieldMergePolicyResolver, error) {
+	err := permission.Check(ctx, "query.itemMergePolicy")
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.Value("mergePolicy").(item.MergePolicy).Resolvers(), nil
+}
+
+func (r *Resolver) ItemProvenance(ctx context.Context, args struct {
+	Id string
+}) ([]*item.FieldProvenanceResolver, error) {
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	itemModel, err := itemService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = item.CheckInNamespace(ctx, "query.itemProvenance", itemModel.NamespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return item.ProvenanceResolvers(itemModel), nil
+}
+
 func (r *Resolver) DeleteItem(ctx context.Context, args struct {
 	Id string
 }) (*graphql.ID, error) {
-	err := permission.Check(ctx, "query.deleteItem")
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	itemModel, err := itemService.FindByID(args.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	itemService := ctx.Value("itemService").(item.Service)
+	err = item.CheckInNamespace(ctx, "query.deleteItem", itemModel.NamespaceID)
+	if err != nil {
+		return nil, err
+	}
 
 	deletedID, err := itemService.DeleteByID(args.Id)
 	result := graphql.ID(deletedID)
 
 	if err == nil {
+		logging.FromContext(ctx).Info("Deleted item", logging.FieldItemID, deletedID)
 		return &result, nil
 	}
 
@@ -159,170 +299,836 @@ func (r *Resolver) DeleteItem(ctx context.Context, args struct {
 }
 
 func (r *Resolver) Item(ctx context.Context, args struct {
-	Id string
+	Id     string
+	Locale *string
 }) (*item.Resolver, error) {
-	err := permission.Check(ctx, "query.item")
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	queryItem, err := itemService.FindByID(args.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	itemService := ctx.Value("itemService").(item.Service)
-
-	queryItem, err := itemService.FindByID(args.Id)
-
-	if err == nil {
-		return &item.Resolver{
-			Model: queryItem,
-		}, nil
+	err = item.CheckInNamespace(ctx, "query.item", queryItem.NamespaceID)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, err
+	return &item.Resolver{
+		Model:  queryItem,
+		Locale: resolveLocale(ctx, args.Locale),
+	}, nil
 }
 
 func (r *Resolver) FindItem(ctx context.Context, args struct {
 	Name        *string
 	NamespaceId *string
+	Locale      *string
 }) (*item.Resolver, error) {
-	err := permission.Check(ctx, "query.findItem")
-	if err != nil {
-		return nil, err
-	}
-
-	itemService := ctx.Value("itemService").(item.Service)
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
 
 	q := itemService.MakeBaseQuery()
 	if args.Name != nil {
-		q["name"] = *args.Name
+		itemService.MakeNameQuery(q, *args.Name)
 	}
 	if args.NamespaceId != nil {
-		q["namespaceId"] = bson.ObjectIdHex(*args.NamespaceId)
+		if !bson.IsObjectIdHex(*args.NamespaceId) {
+			return nil, fmt.Errorf("Invalid namespace id: %v", *args.NamespaceId)
+		}
+		namespaceID := bson.ObjectIdHex(*args.NamespaceId)
+
+		err := item.CheckInNamespace(ctx, "query.findItem", namespaceID)
+		if err != nil {
+			return nil, err
+		}
+		q["namespaceId"] = namespaceID
+	} else {
+		namespaceIDs, err := item.ReadableNamespaces(ctx, "query.findItem", itemService.NamespaceIDs)
+		if err != nil {
+			return nil, err
+		}
+		if namespaceIDs != nil {
+			q["namespaceId"] = bson.M{"$in": namespaceIDs}
+		}
 	}
 	queryItem := itemService.PerformQuery(q)
 
 	if queryItem != nil {
 		return &item.Resolver{
-			Model: queryItem,
+			Model:  queryItem,
+			Locale: resolveLocale(ctx, args.Locale),
 		}, nil
 	}
 
 	return nil, nil
 }
 
-func fetchFFXIVNamespace(ctx context.Context) (string, error) {
-	fetcher := ctx.Value("apigatewayfetcher").(dukgraphql.Fetcher)
+// checkItemWrite checks an item operation and the write permissions of the fields it changes, both in the namespace of the item
+func checkItemWrite(ctx context.Context, name string, namespaceID bson.ObjectId, fields []string) error {
+	err := item.CheckInNamespace(ctx, name, namespaceID)
+	if err != nil {
+		return err
+	}
 
-	namespaceResult, err := fetcher.Fetch(dukgraphql.Request{
-		Query: "query { namespaceByName(name: \"FFXIV\") { _id name } }",
-	})
+	return item.CheckWrite(ctx, namespaceID, fields)
+}
 
+func (r *Resolver) importNamespaceID(ctx context.Context, namespace *string) (bson.ObjectId, error) {
+	namespaceID, err := resolveImportNamespace(ctx, r.Imports.deps.References, namespace)
 	if err != nil {
-		fmt.Printf("Error fetching namespace: %v\n", err)
 		return "", err
 	}
 
-	namespaceResponse := dukgraphql.Response{namespaceResult}
+	return bson.ObjectIdHex(namespaceID), nil
+}
+
+// checkImportWrite checks a permission and the write permissions of the fields of source in the namespace an import writes to
+func (r *Resolver) checkImportWrite(ctx context.Context, name string, namespace *string, source string) error {
+	namespaceID, err := r.importNamespaceID(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	return checkItemWrite(ctx, name, namespaceID, importSourceFields(source))
+}
+
+// logImportStarted ties the import run to the request that started it, the lines of the run itself carry its id
+func logImportStarted(ctx context.Context, reporting importReporting) {
+	reporting.logger(logging.FromContext(ctx)).Info("Started import")
+}
+
+func importResult(reporting importReporting) string {
+	if reporting.DryRun {
+		return reporting.ImportReportID
+	}
+	return "OK"
+}
+
+func (r *Resolver) RcItemImport(ctx context.Context, args struct {
+	Namespace *string
+	DryRun    *bool
+}) (string, error) {
+	err := r.checkImportWrite(ctx, "mutation.rcItemImport", args.Namespace, item.SourceRC)
+	if err != nil {
+		return "No Permission", err
+	}
+
+	reporting, err := r.Imports.StartRC(ctx, args.Namespace, args.DryRun != nil && *args.DryRun)
+	if err != nil {
+		return "Error starting import", err
+	}
+
+	logImportStarted(ctx, reporting)
+	return importResult(reporting), nil
+}
+
+func (r *Resolver) XivdbItemImport(ctx context.Context, args struct {
+	Namespace *string
+	DryRun    *bool
+}) (string, error) {
+	err := r.checkImportWrite(ctx, "mutation.xivdbItemImport", args.Namespace, item.SourceXivdb)
+	if err != nil {
+		return "No Permission", err
+	}
+
+	reporting, err := r.Imports.StartXivdb(ctx, args.Namespace, args.DryRun != nil && *args.DryRun)
+	if err != nil {
+		return "Error starting import", err
+	}
 
-	return namespaceResponse.GetObject("namespaceByName").GetString("_id"), nil
+	logImportStarted(ctx, reporting)
+	return importResult(reporting), nil
 }
 
-func (r *Resolver) RcItemImport(ctx context.Context) (string, error) {
-	err := permission.Check(ctx, "mutation.rcItemImport")
+func (r *Resolver) FileItemImport(ctx context.Context, args struct {
+	Content   string
+	Format    string
+	Locale    *string
+	Namespace *string
+	DryRun    *bool
+}) (string, error) {
+	namespaceID, err := r.importNamespaceID(ctx, args.Namespace)
+	if err != nil {
+		return "Error resolving namespace", err
+	}
+
+	err = item.CheckInNamespace(ctx, "mutation.fileItemImport", namespaceID)
+	if err != nil {
+		return "No Permission", err
+	}
+
+	locale := item.DefaultLocale
+	if args.Locale != nil {
+		locale = *args.Locale
+	}
+
+	itemList, err := readItemDumpString(args.Content, args.Format, locale)
+	if err != nil {
+		return "Error parsing import data", err
+	}
+
+	err = item.CheckWrite(ctx, namespaceID, itemDumpFields(itemList))
 	if err != nil {
 		return "No Permission", err
 	}
 
-	rcItemResponse, err := http.Get("https://rc.dukfaar.com/api/item")
+	reporting, err := r.Imports.StartFile(ctx, itemList, args.Namespace, args.DryRun != nil && *args.DryRun)
+	if err != nil {
+		return "Error starting import", err
+	}
+
+	logImportStarted(ctx, reporting)
+	return importResult(reporting), nil
+}
+
+func (r *Resolver) ImportReports(ctx context.Context, args struct {
+	Source *string
+	First  *int32
+}) ([]*importreport.Resolver, error) {
+	err := permission.Check(ctx, "query.importReports")
+	if err != nil {
+		return nil, err
+	}
+
+	reportService := ctx.Value("importReportService").(importreport.Service)
+
+	reports, err := reportService.List(args.Source, args.First)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*importreport.Resolver, len(reports))
+	for i := range reports {
+		result[i] = &importreport.Resolver{Model: &reports[i]}
+	}
+	return result, nil
+}
+
+func (r *Resolver) ImportReport(ctx context.Context, args struct {
+	Id string
+}) (*importreport.Resolver, error) {
+	err := permission.Check(ctx, "query.importReport")
+	if err != nil {
+		return nil, err
+	}
+
+	reportService := ctx.Value("importReportService").(importreport.Service)
+
+	report, err := reportService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &importreport.Resolver{Model: report}, nil
+}
+
+func (r *Resolver) DeadLetters(ctx context.Context, args struct {
+	Topic *string
+	First *int32
+	After *string
+}) ([]*deadletter.Resolver, error) {
+	err := permission.Check(ctx, "query.deadLetters")
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterService := ctx.Value("deadLetterService").(deadletter.Service)
 
+	deadLetters, err := deadLetterService.List(args.Topic, args.First, args.After)
 	if err != nil {
-		fmt.Printf("Error getting items: %v\n", err)
-		return "Error reading from RC", err
+		return nil, err
+	}
+
+	result := make([]*deadletter.Resolver, len(deadLetters))
+	for i := range deadLetters {
+		result[i] = &deadletter.Resolver{Model: &deadLetters[i]}
 	}
-	defer rcItemResponse.Body.Close()
+	return result, nil
+}
 
-	var itemsData struct {
-		Count int                      `json:"count"`
-		List  []map[string]interface{} `json:"list"`
+func (r *Resolver) DeadLetter(ctx context.Context, args struct {
+	Id string
+}) (*deadletter.Resolver, error) {
+	err := permission.Check(ctx, "query.deadLetter")
+	if err != nil {
+		return nil, err
 	}
-	err = json.NewDecoder(rcItemResponse.Body).Decode(&itemsData)
 
+	deadLetterService := ctx.Value("deadLetterService").(deadletter.Service)
+
+	deadLetter, err := deadLetterService.FindByID(args.Id)
 	if err != nil {
-		fmt.Printf("Error reading items: %v\n", err)
-		return "Error parsing data from RC", err
+		return nil, err
 	}
 
+	return &deadletter.Resolver{Model: deadLetter}, nil
+}
+
+func (r *Resolver) ReplayDeadLetter(ctx context.Context, args struct {
+	Id string
+}) (*graphql.ID, error) {
+	deadLetterService := ctx.Value("deadLetterService").(deadletter.Service)
 	eventbus := ctx.Value("eventbus").(eventbus.EventBus)
-	namespaceId, err := fetchFFXIVNamespace(ctx)
+
+	deadLetter, err := deadLetterService.FindByID(args.Id)
 	if err != nil {
-		return "Error fetching namespace", err
+		return nil, err
 	}
 
-	go func() {
-		for index := range itemsData.List {
-			item := itemsData.List[index]
-			item["namespace"] = namespaceId
-			eventbus.Emit("import.item.by.rcname", item)
-		}
-	}()
+	// a replayed event writes to the namespace in its payload, one without a namespace needs the global permission
+	err = item.CheckInNamespace(ctx, "mutation.replayDeadLetter", deadLetter.NamespaceID())
+	if err != nil {
+		return nil, err
+	}
+
+	err = deadletter.Replay(deadLetterService, eventbus, deadLetter)
+	if err != nil {
+		return nil, err
+	}
 
-	return "OK", nil
+	result := graphql.ID(args.Id)
+	return &result, nil
 }
 
-func (r *Resolver) XivdbItemImport(ctx context.Context) (string, error) {
-	err := permission.Check(ctx, "mutation.xivdbItemImport")
+func (r *Resolver) ReplayAllDeadLetters(ctx context.Context, args struct {
+	Topic string
+}) (int32, error) {
+	err := permission.Check(ctx, "mutation.replayAllDeadLetters")
 	if err != nil {
-		return "No Permission", err
+		return 0, err
 	}
 
-	itemListResponse, err := http.Get("https://api.xivdb.com/item?columns=id")
+	deadLetterService := ctx.Value("deadLetterService").(deadletter.Service)
+	eventbus := ctx.Value("eventbus").(eventbus.EventBus)
 
+	deadLetters, err := deadLetterService.ListByTopic(args.Topic)
 	if err != nil {
-		fmt.Errorf("Error getting item list: %v", err)
-		return "", err
+		return 0, err
+	}
+
+	for i := range deadLetters {
+		err = item.CheckInNamespace(ctx, "mutation.replayDeadLetter", deadLetters[i].NamespaceID())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var replayed int32
+	for i := range deadLetters {
+		err = deadletter.Replay(deadLetterService, eventbus, &deadLetters[i])
+		if err != nil {
+			return replayed, err
+		}
+		replayed++
 	}
-	defer itemListResponse.Body.Close()
 
-	itemList := make([]XivdbItemListResponse, 0)
-	err = json.NewDecoder(itemListResponse.Body).Decode(&itemList)
+	return replayed, nil
+}
+
+func (r *Resolver) ImportReviews(ctx context.Context, args struct {
+	Status *string
+	First  *int32
+	After  *string
+}) ([]*review.Resolver, error) {
+	reviewService := ctx.Value("importReviewService").(review.Service)
 
+	namespaceIDs, err := item.ReadableNamespaces(ctx, "query.importReviews", reviewService.NamespaceIDs)
 	if err != nil {
-		fmt.Errorf("Error reading item list: %v", err)
-		return "", err
+		return nil, err
+	}
+
+	reviews, err := reviewService.List(args.Status, args.First, args.After, namespaceIDs)
+	if err != nil {
+		return nil, err
 	}
 
+	result := make([]*review.Resolver, len(reviews))
+	for i := range reviews {
+		result[i] = &review.Resolver{Model: &reviews[i]}
+	}
+	return result, nil
+}
+
+func (r *Resolver) ImportReview(ctx context.Context, args struct {
+	Id string
+}) (*review.Resolver, error) {
+	reviewService := ctx.Value("importReviewService").(review.Service)
+
+	model, err := reviewService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = item.CheckInNamespace(ctx, "query.importReview", model.NamespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review.Resolver{Model: model}, nil
+}
+
+// decideImportReview stores the decision of a curator and replays the parked event, unless it has been rejected.
+// The permission name is checked in the namespace of the review and in that of the item it is redirected to.
+func decideImportReview(ctx context.Context, name string, id string, status string, itemID *string) (*review.Resolver, error) {
+	reviewService := ctx.Value("importReviewService").(review.Service)
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
 	eventbus := ctx.Value("eventbus").(eventbus.EventBus)
 
-	go func() {
-		namespaceId, err := fetchFFXIVNamespace(ctx)
+	model, err := reviewService.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// a rejected event is not replayed and writes nothing
+	fields := []string{}
+	if status != review.StatusRejected {
+		fields, err = reviewFields(model)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = checkItemWrite(ctx, name, model.NamespaceID, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *bson.ObjectId
+	if itemID != nil {
+		if !bson.IsObjectIdHex(*itemID) {
+			return nil, fmt.Errorf("Invalid item id: %v", *itemID)
+		}
+		targetModel, err := itemService.FindByID(*itemID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkItemWrite(ctx, name, targetModel.NamespaceID, fields); err != nil {
+			return nil, err
+		}
+		target = &targetModel.ID
+	}
+
+	err = model.Decide(status, target, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = reviewService.Decide(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != review.StatusRejected {
+		err = review.Replay(eventbus, model)
 		if err != nil {
-			return
+			return nil, err
 		}
+	}
+
+	return &review.Resolver{Model: model}, nil
+}
+
+func (r *Resolver) AcceptImportReview(ctx context.Context, args struct {
+	Id     string
+	ItemId *string
+}) (*review.Resolver, error) {
+	return decideImportReview(ctx, "mutation.acceptImportReview", args.Id, review.StatusAccepted, args.ItemId)
+}
+
+func (r *Resolver) RejectImportReview(ctx context.Context, args struct {
+	Id string
+}) (*review.Resolver, error) {
+	return decideImportReview(ctx, "mutation.rejectImportReview", args.Id, review.StatusRejected, nil)
+}
+
+func (r *Resolver) RedirectImportReview(ctx context.Context, args struct {
+	Id     string
+	ItemId string
+}) (*review.Resolver, error) {
+	return decideImportReview(ctx, "mutation.redirectImportReview", args.Id, review.StatusRedirected, &args.ItemId)
+}
+
+func (r *Resolver) ImportSchedules(ctx context.Context) ([]*schedule.Resolver, error) {
+	err := permission.Check(ctx, "query.importSchedules")
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleService := ctx.Value("scheduleService").(schedule.Service)
+
+	schedules, err := scheduleService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*schedule.Resolver, len(schedules))
+	for i := range schedules {
+		result[i] = &schedule.Resolver{Model: &schedules[i]}
+	}
+	return result, nil
+}
 
-		for index, _ := range itemList {
-			item := itemList[index]
-			itemData, err := FetchXivdbItemData(item.ID)
+func (r *Resolver) CreateImportSchedule(ctx context.Context, args struct {
+	Source    string
+	Cron      string
+	Namespace *string
+	Enabled   *bool
+	DryRun    *bool
+}) (*schedule.Resolver, error) {
+	if !isSchedulableImportSource(args.Source) {
+		return nil, fmt.Errorf("Imports from %v can not be scheduled", args.Source)
+	}
+
+	err := r.checkImportWrite(ctx, "mutation.createImportSchedule", args.Namespace, args.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleService := ctx.Value("scheduleService").(schedule.Service)
 
-			if err != nil {
-				fmt.Printf("Skipping item with id: %v\n", item.ID)
-				time.Sleep(time.Millisecond * 200)
-				continue
-			}
+	newModel, err := scheduleService.Create(&schedule.Model{
+		Source:    args.Source,
+		Cron:      args.Cron,
+		Namespace: args.Namespace,
+		Enabled:   args.Enabled == nil || *args.Enabled,
+		DryRun:    args.DryRun != nil && *args.DryRun,
+	})
 
-			resultItem := make(map[string]interface{})
-			json.Unmarshal(itemData, &resultItem)
-			resultItem["namespace"] = namespaceId
+	if err == nil {
+		return &schedule.Resolver{Model: newModel}, nil
+	}
 
-			delete(resultItem, "special_shops_obtain")
-			delete(resultItem, "special_shops_currency")
+	return nil, err
+}
 
-			err = eventbus.Emit("import.item.by.xivdbid", resultItem)
+func (r *Resolver) UpdateImportSchedule(ctx context.Context, args struct {
+	Id        string
+	Cron      *string
+	Namespace *string
+	Enabled   *bool
+	DryRun    *bool
+}) (*schedule.Resolver, error) {
+	err := permission.Check(ctx, "mutation.updateImportSchedule")
+	if err != nil {
+		return nil, err
+	}
 
-			if err != nil {
-				fmt.Println(err)
-			}
+	scheduleService := ctx.Value("scheduleService").(schedule.Service)
 
-			time.Sleep(time.Millisecond * 200)
+	// moving a schedule needs the same permissions in the new namespace as creating it there
+	if args.Namespace != nil {
+		model, err := scheduleService.FindByID(args.Id)
+		if err != nil {
+			return nil, err
 		}
-	}()
 
-	return "OK", nil
+		err = r.checkImportWrite(ctx, "mutation.updateImportSchedule", args.Namespace, model.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newModel, err := scheduleService.Update(args.Id, args.Cron, args.Namespace, args.Enabled, args.DryRun)
+
+	if err == nil {
+		return &schedule.Resolver{Model: newModel}, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) DeleteImportSchedule(ctx context.Context, args struct {
+	Id string
+}) (*graphql.ID, error) {
+	err := permission.Check(ctx, "mutation.deleteImportSchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleService := ctx.Value("scheduleService").(schedule.Service)
+
+	deletedID, err := scheduleService.DeleteByID(args.Id)
+	result := graphql.ID(deletedID)
+
+	if err == nil {
+		return &result, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) ImportSources(ctx context.Context) ([]*importsource.Resolver, error) {
+	err := permission.Check(ctx, "query.importSources")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	sources, err := sourceService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*importsource.Resolver, len(sources))
+	for i := range sources {
+		result[i] = &importsource.Resolver{Model: &sources[i]}
+	}
+	return result, nil
+}
+
+func (r *Resolver) ImportSource(ctx context.Context, args struct {
+	Id string
+}) (*importsource.Resolver, error) {
+	err := permission.Check(ctx, "query.importSource")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	model, err := sourceService.FindByID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &importsource.Resolver{Model: model}, nil
+}
+
+func (r *Resolver) CreateImportSource(ctx context.Context, args struct {
+	Input importsource.Input
+}) (*importsource.Resolver, error) {
+	err := permission.Check(ctx, "mutation.createImportSource")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	newModel, err := sourceService.Create(args.Input.Model())
+
+	if err == nil {
+		return &importsource.Resolver{Model: newModel}, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) UpdateImportSource(ctx context.Context, args struct {
+	Id    string
+	Input importsource.Input
+}) (*importsource.Resolver, error) {
+	err := permission.Check(ctx, "mutation.updateImportSource")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	newModel, err := sourceService.Update(args.Id, args.Input.Model())
+
+	if err == nil {
+		return &importsource.Resolver{Model: newModel}, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) DeleteImportSource(ctx context.Context, args struct {
+	Id string
+}) (*graphql.ID, error) {
+	err := permission.Check(ctx, "mutation.deleteImportSource")
+	if err != nil {
+		return nil, err
+	}
+
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	deletedID, err := sourceService.DeleteByID(args.Id)
+	result := graphql.ID(deletedID)
+
+	if err == nil {
+		return &result, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) RunImportSource(ctx context.Context, args struct {
+	Id        string
+	Namespace *string
+	DryRun    *bool
+}) (string, error) {
+	sourceService := ctx.Value("importSourceService").(importsource.Service)
+
+	source, err := sourceService.FindByID(args.Id)
+	if err != nil {
+		return "Error loading import source", err
+	}
+
+	err = r.checkImportWrite(ctx, "mutation.runImportSource", args.Namespace, source.Name)
+	if err != nil {
+		return "No Permission", err
+	}
+
+	reporting, err := r.Imports.StartSource(ctx, source, args.Namespace, args.DryRun != nil && *args.DryRun)
+	if err != nil {
+		return "Error starting import", err
+	}
+
+	logImportStarted(ctx, reporting)
+	return importResult(reporting), nil
+}
+
+func (r *Resolver) ExportItems(ctx context.Context, args struct {
+	Format string
+	Filter *export.Filter
+}) (*export.Resolver, error) {
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+	exportService := ctx.Value("exportService").(export.Service)
+
+	filter := export.Filter{}
+	if args.Filter != nil {
+		filter = *args.Filter
+	}
+
+	// the download checks again, this rejects a namespace the caller can not export from right away
+	if _, err := filter.Query(ctx, itemService, "mutation.exportItems"); err != nil {
+		return nil, err
+	}
+
+	model, err := exportService.Create(strings.ToLower(args.Format), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export.Resolver{Model: model}, nil
+}
+
+func (r *Resolver) Snapshots(ctx context.Context) ([]*snapshot.Resolver, error) {
+	err := permission.Check(ctx, "query.snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotService := ctx.Value("snapshotService").(snapshot.Service)
+
+	snapshots, err := snapshotService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*snapshot.Resolver, len(snapshots))
+	for i := range snapshots {
+		result[i] = &snapshot.Resolver{Model: &snapshots[i]}
+	}
+	return result, nil
+}
+
+func (r *Resolver) CreateSnapshot(ctx context.Context, args struct {
+	Label string
+}) (*snapshot.Resolver, error) {
+	err := permission.Check(ctx, "mutation.createSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotService := ctx.Value("snapshotService").(snapshot.Service)
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	model, err := snapshotService.Create(args.Label, func(handler func(*item.Model) error) error {
+		return itemService.Iterate(itemService.MakeBaseQuery(), handler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot.Resolver{Model: model}, nil
+}
+
+func (r *Resolver) RestoreSnapshot(ctx context.Context, args struct {
+	Id   string
+	Mode string
+}) (*snapshot.RestoreResultResolver, error) {
+	err := permission.Check(ctx, "mutation.restoreSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotService := ctx.Value("snapshotService").(snapshot.Service)
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	if _, err := snapshotService.FindByID(args.Id); err != nil {
+		return nil, err
+	}
+
+	namespaceIDs, err := snapshot.AffectedNamespaces(snapshotService, itemService, args.Id, args.Mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, namespaceID := range namespaceIDs {
+		if err := item.CheckWrite(ctx, namespaceID, item.WritableFields()); err != nil {
+			return nil, err
+		}
+	}
+
+	logger := logging.FromContext(ctx).With("snapshotId", args.Id, "mode", args.Mode)
+
+	result, err := snapshot.Restore(logger, snapshotService, itemService, args.Id, args.Mode)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Restored snapshot", "created", result.Created, "updated", result.Updated, "deleted", result.Deleted, "failed", result.Failed)
+
+	return &snapshot.RestoreResultResolver{Result: result}, nil
+}
+
+func (r *Resolver) DeleteSnapshot(ctx context.Context, args struct {
+	Id string
+}) (*graphql.ID, error) {
+	err := permission.Check(ctx, "mutation.deleteSnapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotService := ctx.Value("snapshotService").(snapshot.Service)
+
+	deletedID, err := snapshotService.DeleteByID(args.Id)
+	result := graphql.ID(deletedID)
+
+	if err == nil {
+		return &result, nil
+	}
+
+	return nil, err
+}
+
+func (r *Resolver) LogLevel(ctx context.Context) (string, error) {
+	err := permission.Check(ctx, "query.logLevel")
+	if err != nil {
+		return "", err
+	}
+
+	return logging.Root().Level().String(), nil
+}
+
+// SetLogLevel changes the level of every logger of this instance until it restarts
+func (r *Resolver) SetLogLevel(ctx context.Context, args struct {
+	Level string
+}) (string, error) {
+	err := permission.Check(ctx, "mutation.setLogLevel")
+	if err != nil {
+		return "", err
+	}
+
+	level, err := logging.ParseLevel(args.Level)
+	if err != nil {
+		return "", err
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Changing log level", "from", logging.Root().Level().String(), "to", level.String())
+	logging.Root().SetLevel(level)
+
+	return level.String(), nil
 }
@@ -0,0 +1,118 @@
+package review
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	ReasonNameMatch          = "nameMatch"
+	ReasonMultipleCandidates = "multipleCandidates"
+)
+
+const (
+	StatusPending    = "PENDING"
+	StatusAccepted   = "ACCEPTED"
+	StatusRejected   = "REJECTED"
+	StatusRedirected = "REDIRECTED"
+)
+
+// Model is an import event that could only be matched to existing items by name.
+// Once decided it is kept, so later runs of the same xivdb item follow the curator's decision.
+type Model struct {
+	ID          bson.ObjectId   `json:"_id,omitempty" bson:"_id,omitempty"`
+	Topic       string          `json:"topic" bson:"topic"`
+	Source      string          `json:"source" bson:"source"`
+	XivdbID     int32           `json:"xivdbId" bson:"xivdbId"`
+	Name        string          `json:"name" bson:"name"`
+	NamespaceID bson.ObjectId   `json:"namespaceId,omitempty" bson:"namespaceId,omitempty"`
+	Payload     string          `json:"payload" bson:"payload"`
+	Reason      string          `json:"reason" bson:"reason"`
+	Candidates  []bson.ObjectId `json:"candidates" bson:"candidates"`
+	Status      string          `json:"status" bson:"status"`
+	ItemID      *bson.ObjectId  `json:"itemId,omitempty" bson:"itemId,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt" bson:"createdAt"`
+	DecidedAt   *time.Time      `json:"decidedAt,omitempty" bson:"decidedAt,omitempty"`
+}
+
+// ReasonFor tells why an event with the given name matches needs a curator, or "" if there is nothing to match against
+func ReasonFor(candidates []item.Model) string {
+	switch len(candidates) {
+	case 0:
+		return ""
+	case 1:
+		return ReasonNameMatch
+	}
+	return ReasonMultipleCandidates
+}
+
+func (m *Model) isCandidate(id bson.ObjectId) bool {
+	for _, candidate := range m.Candidates {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Decide records the decision of a curator. Accepting defaults to the only candidate, redirecting may target any item.
+func (m *Model) Decide(status string, itemID *bson.ObjectId, now time.Time) error {
+	if m.Status != StatusPending {
+		return errors.New("Import review has already been decided")
+	}
+
+	switch status {
+	case StatusAccepted:
+		if itemID == nil {
+			if len(m.Candidates) != 1 {
+				return errors.New("Accepting a review with several candidates needs an item id")
+			}
+			itemID = &m.Candidates[0]
+		}
+		if !m.isCandidate(*itemID) {
+			return errors.New("Item is not a candidate of this review, redirect instead")
+		}
+	case StatusRedirected:
+		if itemID == nil {
+			return errors.New("Redirecting a review needs an item id")
+		}
+	case StatusRejected:
+		itemID = nil
+	default:
+		return errors.New("Unknown import review status: " + status)
+	}
+
+	m.Status = status
+	m.ItemID = itemID
+	m.DecidedAt = &now
+	return nil
+}
+
+var GraphQLType = `
+	enum ImportReviewStatus {
+		PENDING
+		ACCEPTED
+		REJECTED
+		REDIRECTED
+	}
+
+	type ImportReview {
+		_id: ID!
+		topic: String!
+		source: String!
+		xivdbId: Int!
+		name: String!
+		namespaceId: ID
+		payload: String!
+		reason: String!
+		candidateIds: [ID!]!
+		candidates: [Item!]!
+		status: ImportReviewStatus!
+		itemId: ID
+		createdAt: String!
+		decidedAt: String
+	}
+`
@@ -0,0 +1,87 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestReasonFor(t *testing.T) {
+	tests := []struct {
+		candidates int
+		want       string
+	}{
+		{0, ""},
+		{1, ReasonNameMatch},
+		{3, ReasonMultipleCandidates},
+	}
+
+	for _, tt := range tests {
+		if got := ReasonFor(make([]item.Model, tt.candidates)); got != tt.want {
+			t.Errorf("ReasonFor(%v candidates) = %q, want %q", tt.candidates, got, tt.want)
+		}
+	}
+}
+
+func TestModel_Decide(t *testing.T) {
+	first := bson.ObjectIdHex("00112233445566778899aabb")
+	second := bson.ObjectIdHex("10112233445566778899aabb")
+	other := bson.ObjectIdHex("20112233445566778899aabb")
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		candidates []bson.ObjectId
+		status     string
+		itemID     *bson.ObjectId
+		wantItemID *bson.ObjectId
+		wantErr    bool
+	}{
+		{"accept single candidate", []bson.ObjectId{first}, StatusAccepted, nil, &first, false},
+		{"accept needs choice", []bson.ObjectId{first, second}, StatusAccepted, nil, nil, true},
+		{"accept chosen candidate", []bson.ObjectId{first, second}, StatusAccepted, &second, &second, false},
+		{"accept non candidate", []bson.ObjectId{first}, StatusAccepted, &other, nil, true},
+		{"redirect", []bson.ObjectId{first}, StatusRedirected, &other, &other, false},
+		{"redirect needs target", []bson.ObjectId{first}, StatusRedirected, nil, nil, true},
+		{"reject", []bson.ObjectId{first}, StatusRejected, &first, nil, false},
+		{"unknown status", []bson.ObjectId{first}, StatusPending, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &Model{Candidates: tt.candidates, Status: StatusPending}
+
+			err := model.Decide(tt.status, tt.itemID, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decide() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if model.Status != StatusPending {
+					t.Errorf("Decide() changed status to %v on error", model.Status)
+				}
+				return
+			}
+
+			if model.Status != tt.status || model.DecidedAt == nil {
+				t.Errorf("Decide() status = %v, decidedAt = %v", model.Status, model.DecidedAt)
+			}
+			if (model.ItemID == nil) != (tt.wantItemID == nil) || (model.ItemID != nil && *model.ItemID != *tt.wantItemID) {
+				t.Errorf("Decide() itemId = %v, want %v", model.ItemID, tt.wantItemID)
+			}
+		})
+	}
+}
+
+func TestModel_DecideTwice(t *testing.T) {
+	id := bson.ObjectIdHex("00112233445566778899aabb")
+	model := &Model{Candidates: []bson.ObjectId{id}, Status: StatusPending}
+
+	if err := model.Decide(StatusRejected, nil, time.Now()); err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+	if err := model.Decide(StatusAccepted, nil, time.Now()); err == nil {
+		t.Errorf("Decide() on a decided review should fail")
+	}
+}
@@ -0,0 +1,102 @@
+package review
+
+import (
+	"context"
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Topic() string {
+	return r.Model.Topic
+}
+
+func (r *Resolver) Source() string {
+	return r.Model.Source
+}
+
+func (r *Resolver) XivdbID() int32 {
+	return r.Model.XivdbID
+}
+
+func (r *Resolver) Name() string {
+	return r.Model.Name
+}
+
+func (r *Resolver) NamespaceID() *graphql.ID {
+	if r.Model.NamespaceID == "" {
+		return nil
+	}
+
+	id := graphql.ID(r.Model.NamespaceID.Hex())
+	return &id
+}
+
+func (r *Resolver) Payload() string {
+	return r.Model.Payload
+}
+
+func (r *Resolver) Reason() string {
+	return r.Model.Reason
+}
+
+func (r *Resolver) CandidateIDs() []graphql.ID {
+	result := make([]graphql.ID, len(r.Model.Candidates))
+	for i, candidate := range r.Model.Candidates {
+		result[i] = graphql.ID(candidate.Hex())
+	}
+	return result
+}
+
+// Candidates skips candidates that have been deleted since the review was parked
+func (r *Resolver) Candidates(ctx context.Context) ([]*item.Resolver, error) {
+	itemService := item.WithContext(ctx, ctx.Value("itemService").(item.Service))
+
+	result := make([]*item.Resolver, 0, len(r.Model.Candidates))
+	for _, candidate := range r.Model.Candidates {
+		model, err := itemService.FindByID(candidate.Hex())
+		if err != nil {
+			if err.Error() == "not found" {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, &item.Resolver{Model: model})
+	}
+	return result, nil
+}
+
+func (r *Resolver) Status() string {
+	return r.Model.Status
+}
+
+func (r *Resolver) ItemID() *graphql.ID {
+	if r.Model.ItemID == nil {
+		return nil
+	}
+
+	id := graphql.ID(r.Model.ItemID.Hex())
+	return &id
+}
+
+func (r *Resolver) CreatedAt() string {
+	return r.Model.CreatedAt.Format(time.RFC3339)
+}
+
+func (r *Resolver) DecidedAt() *string {
+	if r.Model.DecidedAt == nil {
+		return nil
+	}
+
+	decidedAt := r.Model.DecidedAt.Format(time.RFC3339)
+	return &decidedAt
+}
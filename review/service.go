@@ -0,0 +1,135 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type Service interface {
+	Park(model *Model) (*Model, error)
+	FindByID(string) (*Model, error)
+	FindDecision(xivdbID int32) (*Model, error)
+	List(status *string, first *int32, after *string, namespaceIDs []bson.ObjectId) ([]Model, error)
+	NamespaceIDs() ([]bson.ObjectId, error)
+	Decide(model *Model) error
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("importReviews"),
+	}
+}
+
+// Park stores model as pending review. A pending review of the same xivdb item is refreshed instead of queued twice.
+func (s *MgoService) Park(model *Model) (*Model, error) {
+	selector := bson.M{"xivdbId": model.XivdbID, "status": StatusPending}
+
+	_, err := s.collection.Upsert(selector, bson.M{
+		"$set": bson.M{
+			"topic":       model.Topic,
+			"source":      model.Source,
+			"name":        model.Name,
+			"namespaceId": model.NamespaceID,
+			"payload":     model.Payload,
+			"reason":      model.Reason,
+			"candidates":  model.Candidates,
+		},
+		"$setOnInsert": bson.M{
+			"_id":       bson.NewObjectId(),
+			"createdAt": time.Now(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result Model
+	err = s.collection.Find(selector).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, fmt.Errorf("Invalid import review id: %v", id)
+	}
+
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+// FindDecision returns the latest decided review of a xivdb item
+func (s *MgoService) FindDecision(xivdbID int32) (*Model, error) {
+	var result Model
+
+	err := s.collection.Find(bson.M{
+		"xivdbId": xivdbID,
+		"status":  bson.M{"$ne": StatusPending},
+	}).Sort("-decidedAt").One(&result)
+
+	return &result, err
+}
+
+// List pages through the reviews by id. Unless namespaceIDs is nil only the reviews of those namespaces are listed.
+func (s *MgoService) List(status *string, first *int32, after *string, namespaceIDs []bson.ObjectId) ([]Model, error) {
+	query := bson.M{}
+	if status != nil {
+		query["status"] = *status
+	}
+	if after != nil {
+		if !bson.IsObjectIdHex(*after) {
+			return nil, fmt.Errorf("Invalid cursor: %v", *after)
+		}
+		query["_id"] = bson.M{"$gt": bson.ObjectIdHex(*after)}
+	}
+	if namespaceIDs != nil {
+		query["namespaceId"] = bson.M{"$in": namespaceIDs}
+	}
+
+	limit := 100
+	if first != nil {
+		limit = int(*first)
+	}
+
+	var result []Model
+	err := s.collection.Find(query).Sort("_id").Limit(limit).All(&result)
+	return result, err
+}
+
+func (s *MgoService) NamespaceIDs() ([]bson.ObjectId, error) {
+	var result []bson.ObjectId
+
+	err := s.collection.Find(bson.M{}).Distinct("namespaceId", &result)
+
+	return result, err
+}
+
+// Decide stores the decision made by model.Decide, failing if the review has been decided concurrently
+func (s *MgoService) Decide(model *Model) error {
+	return s.collection.Update(bson.M{"_id": model.ID, "status": StatusPending}, bson.M{
+		"$set": bson.M{
+			"status":    model.Status,
+			"itemId":    model.ItemID,
+			"decidedAt": model.DecidedAt,
+		},
+	})
+}
+
+// Replay emits the parked event again, so the importer applies the decision
+func Replay(bus eventbus.EventBus, model *Model) error {
+	return bus.Emit(model.Topic, json.RawMessage(model.Payload))
+}
@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+type Model struct {
+	ID         bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Source     string        `json:"source" bson:"source"`
+	Cron       string        `json:"cron" bson:"cron"`
+	Namespace  *string       `json:"namespace,omitempty" bson:"namespace,omitempty"`
+	Enabled    bool          `json:"enabled" bson:"enabled"`
+	DryRun     bool          `json:"dryRun" bson:"dryRun"`
+	NextRunAt  time.Time     `json:"nextRunAt" bson:"nextRunAt"`
+	LastRunAt  *time.Time    `json:"lastRunAt,omitempty" bson:"lastRunAt,omitempty"`
+	LastResult string        `json:"lastResult,omitempty" bson:"lastResult,omitempty"`
+	LastError  string        `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	LeaseOwner string        `json:"leaseOwner,omitempty" bson:"leaseOwner,omitempty"`
+	LeaseUntil *time.Time    `json:"leaseUntil,omitempty" bson:"leaseUntil,omitempty"`
+}
+
+var GraphQLType = `
+	type ImportSchedule {
+		_id: ID!
+		source: String!
+		cron: String!
+		namespace: String
+		enabled: Boolean!
+		dryRun: Boolean!
+		nextRunAt: String!
+		lastRunAt: String
+		lastResult: String
+		lastError: String
+	}
+`
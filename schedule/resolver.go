@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Source() string {
+	return r.Model.Source
+}
+
+func (r *Resolver) Cron() string {
+	return r.Model.Cron
+}
+
+func (r *Resolver) Namespace() *string {
+	return r.Model.Namespace
+}
+
+func (r *Resolver) Enabled() bool {
+	return r.Model.Enabled
+}
+
+func (r *Resolver) DryRun() bool {
+	return r.Model.DryRun
+}
+
+func (r *Resolver) NextRunAt() string {
+	return r.Model.NextRunAt.Format(time.RFC3339)
+}
+
+func (r *Resolver) LastRunAt() *string {
+	if r.Model.LastRunAt == nil {
+		return nil
+	}
+
+	result := r.Model.LastRunAt.Format(time.RFC3339)
+	return &result
+}
+
+func (r *Resolver) LastResult() *string {
+	if r.Model.LastResult == "" {
+		return nil
+	}
+	return &r.Model.LastResult
+}
+
+func (r *Resolver) LastError() *string {
+	if r.Model.LastError == "" {
+		return nil
+	}
+	return &r.Model.LastError
+}
@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/dukfaar/itemBackend/logging"
+	mgo "github.com/globalsign/mgo"
+)
+
+type TriggerFunc func(model *Model) (string, error)
+
+// Scheduler polls for due schedules and triggers their import. Every replica runs a Scheduler,
+// the lease taken by AcquireDue makes sure each run is only triggered once.
+type Scheduler struct {
+	Service      Service
+	Owner        string
+	PollInterval time.Duration
+	Lease        time.Duration
+	Trigger      TriggerFunc
+	Logger       *logging.Logger
+}
+
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(time.Now())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(now time.Time) {
+	for {
+		model, err := s.Service.AcquireDue(s.Owner, now, s.Lease)
+		if err == mgo.ErrNotFound {
+			return
+		}
+		if err != nil {
+			s.Logger.Error("Acquiring due import schedules failed", logging.FieldError, err)
+			return
+		}
+
+		logger := s.Logger.With("scheduleId", model.ID.Hex(), "source", model.Source)
+
+		result, runErr := s.Trigger(model)
+		if runErr != nil {
+			logger.Error("Running scheduled import failed", logging.FieldError, runErr)
+		} else {
+			logger.Info("Started scheduled import", logging.FieldImportRunID, result)
+		}
+
+		err = s.Service.Complete(model, s.Owner, now, result, runErr)
+		if err != nil {
+			logger.Error("Completing import schedule failed", logging.FieldError, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dukfaar/itemBackend/logging"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+func newSchedule(id string, source string, nextRunAt time.Time) *Model {
+	return &Model{
+		ID:        bson.ObjectIdHex(id),
+		Source:    source,
+		Cron:      "*/5 * * * *",
+		Enabled:   true,
+		NextRunAt: nextRunAt,
+	}
+}
+
+func newScheduler(trigger TriggerFunc) *Scheduler {
+	return &Scheduler{
+		Service: &MgoService{},
+		Owner:   "replica-1",
+		Lease:   time.Minute,
+		Logger:  logging.New(ioutil.Discard, logging.LevelError),
+		Trigger: trigger,
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	collection := &recordingCollection{due: []*Model{
+		newSchedule("000000000000000000000001", "due", now),
+		newSchedule("000000000000000000000002", "expired", now.Add(-time.Hour)),
+	}}
+	stubCollection(t, collection)
+
+	var triggered []string
+	scheduler := newScheduler(func(model *Model) (string, error) {
+		if model.LeaseOwner != "replica-1" {
+			t.Errorf("Trigger() got %v leased to %q, want it leased to the scheduler", model.Source, model.LeaseOwner)
+		}
+		triggered = append(triggered, model.Source)
+		return "run-" + model.Source, nil
+	})
+
+	scheduler.runDue(now)
+
+	if want := []string{"due", "expired"}; !reflect.DeepEqual(triggered, want) {
+		t.Errorf("runDue() triggered %v, want %v", triggered, want)
+	}
+	if len(collection.queries) != 3 {
+		t.Errorf("runDue() acquired %v times, want until nothing is due", len(collection.queries))
+	}
+
+	wantSelectors := []bson.M{
+		{"_id": bson.ObjectIdHex("000000000000000000000001"), "leaseOwner": "replica-1"},
+		{"_id": bson.ObjectIdHex("000000000000000000000002"), "leaseOwner": "replica-1"},
+	}
+	if !reflect.DeepEqual(collection.selectors, wantSelectors) {
+		t.Errorf("runDue() completed %#v, want %#v", collection.selectors, wantSelectors)
+	}
+	for _, update := range collection.updates {
+		set := update["$set"].(bson.M)
+		if want := now.Add(5 * time.Minute); set["nextRunAt"] != want {
+			t.Errorf("runDue() set nextRunAt %v, want %v", set["nextRunAt"], want)
+		}
+	}
+}
+
+func TestScheduler_RunDueLeaseTakenOver(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// the run outlasted the lease and another replica took the schedule over, so completing it matches nothing
+	collection := &recordingCollection{
+		due: []*Model{
+			newSchedule("000000000000000000000001", "due", now),
+			newSchedule("000000000000000000000002", "next", now),
+		},
+		updateErr: mgo.ErrNotFound,
+	}
+	stubCollection(t, collection)
+
+	calls := 0
+	scheduler := newScheduler(func(*Model) (string, error) {
+		calls++
+		return "run-1", nil
+	})
+
+	scheduler.runDue(now)
+
+	if calls != 1 {
+		t.Errorf("runDue() triggered %v times, want once", calls)
+	}
+	wantSelectors := []bson.M{{"_id": bson.ObjectIdHex("000000000000000000000001"), "leaseOwner": "replica-1"}}
+	if !reflect.DeepEqual(collection.selectors, wantSelectors) {
+		t.Errorf("runDue() completed %#v, want %#v", collection.selectors, wantSelectors)
+	}
+}
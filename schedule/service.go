@@ -0,0 +1,188 @@
+package schedule
+
+import (
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/robfig/cron"
+)
+
+type Service interface {
+	Create(*Model) (*Model, error)
+	Update(id string, cronSpec *string, namespace *string, enabled *bool, dryRun *bool) (*Model, error)
+	DeleteByID(id string) (string, error)
+	FindByID(string) (*Model, error)
+	List() ([]Model, error)
+
+	AcquireDue(owner string, now time.Time, lease time.Duration) (*Model, error)
+	Complete(model *Model, owner string, ranAt time.Time, result string, runErr error) error
+}
+
+type MgoService struct {
+	db         *mgo.Database
+	collection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:         db,
+		collection: db.C("importSchedules"),
+	}
+}
+
+func NextRun(cronSpec string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(cronSpec)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return schedule.Next(after), nil
+}
+
+func (s *MgoService) Create(model *Model) (*Model, error) {
+	nextRunAt, err := NextRun(model.Cron, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	model.ID = bson.NewObjectId()
+	model.NextRunAt = nextRunAt
+
+	err = s.collection.Insert(model)
+
+	return model, err
+}
+
+func (s *MgoService) Update(id string, cronSpec *string, namespace *string, enabled *bool, dryRun *bool) (*Model, error) {
+	set := bson.M{}
+
+	if cronSpec != nil {
+		nextRunAt, err := NextRun(*cronSpec, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		set["cron"] = *cronSpec
+		set["nextRunAt"] = nextRunAt
+	}
+	if namespace != nil {
+		set["namespace"] = *namespace
+	}
+	if enabled != nil {
+		set["enabled"] = *enabled
+	}
+	if dryRun != nil {
+		set["dryRun"] = *dryRun
+	}
+
+	if len(set) > 0 {
+		err := s.collection.UpdateId(bson.ObjectIdHex(id), bson.M{"$set": set})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.FindByID(id)
+}
+
+func (s *MgoService) DeleteByID(id string) (string, error) {
+	err := s.collection.RemoveId(bson.ObjectIdHex(id))
+
+	return id, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) List() ([]Model, error) {
+	var result []Model
+
+	err := s.collection.Find(bson.M{}).Sort("source", "_id").All(&result)
+
+	return result, err
+}
+
+// findAndModify and updateOne run the lease queries against the collection, tests replace them to see the documents sent
+var findAndModify = func(collection *mgo.Collection, query bson.M, change mgo.Change, result interface{}) error {
+	_, err := collection.Find(query).Apply(change, result)
+	return err
+}
+
+var updateOne = func(collection *mgo.Collection, selector bson.M, update bson.M) error {
+	return collection.Update(selector, update)
+}
+
+// dueQuery selects the enabled schedules due at now that are not leased, or whose lease has expired
+func dueQuery(now time.Time) bson.M {
+	return bson.M{
+		"enabled":   true,
+		"nextRunAt": bson.M{"$lte": now},
+		"$or": []bson.M{
+			{"leaseUntil": bson.M{"$exists": false}},
+			{"leaseUntil": bson.M{"$lt": now}},
+		},
+	}
+}
+
+func leaseUpdate(owner string, leaseUntil time.Time) bson.M {
+	return bson.M{"$set": bson.M{
+		"leaseOwner": owner,
+		"leaseUntil": leaseUntil,
+	}}
+}
+
+// leaseOwnerQuery selects the schedule only while owner still holds its lease,
+// a replica whose lease expired and was taken over must not complete the run
+func leaseOwnerQuery(id bson.ObjectId, owner string) bson.M {
+	return bson.M{
+		"_id":        id,
+		"leaseOwner": owner,
+	}
+}
+
+// AcquireDue atomically leases one due schedule to owner, so only a single replica triggers each run.
+// It returns mgo.ErrNotFound when nothing is due.
+func (s *MgoService) AcquireDue(owner string, now time.Time, lease time.Duration) (*Model, error) {
+	var result Model
+	err := findAndModify(s.collection, dueQuery(now), mgo.Change{
+		Update:    leaseUpdate(owner, now.Add(lease)),
+		ReturnNew: true,
+	}, &result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *MgoService) Complete(model *Model, owner string, ranAt time.Time, result string, runErr error) error {
+	nextRunAt, err := NextRun(model.Cron, ranAt)
+	if err != nil {
+		return err
+	}
+
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+
+	return updateOne(s.collection, leaseOwnerQuery(model.ID, owner), bson.M{
+		"$set": bson.M{
+			"nextRunAt":  nextRunAt,
+			"lastRunAt":  ranAt,
+			"lastResult": result,
+			"lastError":  lastError,
+		},
+		"$unset": bson.M{
+			"leaseOwner": "",
+			"leaseUntil": "",
+		},
+	})
+}
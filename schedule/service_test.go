@@ -0,0 +1,160 @@
+package schedule
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// recordingCollection stands in for the importSchedules collection. It records the query documents
+// MgoService sends and answers AcquireDue with the schedules in due, one per call.
+type recordingCollection struct {
+	due       []*Model
+	updateErr error
+
+	queries   []bson.M
+	changes   []mgo.Change
+	selectors []bson.M
+	updates   []bson.M
+}
+
+func stubCollection(t *testing.T, collection *recordingCollection) {
+	originalFindAndModify, originalUpdateOne := findAndModify, updateOne
+
+	findAndModify = func(_ *mgo.Collection, query bson.M, change mgo.Change, result interface{}) error {
+		collection.queries = append(collection.queries, query)
+		collection.changes = append(collection.changes, change)
+		if len(collection.due) == 0 {
+			return mgo.ErrNotFound
+		}
+
+		model := *collection.due[0]
+		collection.due = collection.due[1:]
+
+		set := change.Update.(bson.M)["$set"].(bson.M)
+		leaseUntil := set["leaseUntil"].(time.Time)
+		model.LeaseOwner = set["leaseOwner"].(string)
+		model.LeaseUntil = &leaseUntil
+
+		*result.(*Model) = model
+		return nil
+	}
+	updateOne = func(_ *mgo.Collection, selector bson.M, update bson.M) error {
+		collection.selectors = append(collection.selectors, selector)
+		collection.updates = append(collection.updates, update)
+		return collection.updateErr
+	}
+
+	t.Cleanup(func() { findAndModify, updateOne = originalFindAndModify, originalUpdateOne })
+}
+
+func TestMgoService_AcquireDue(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	due := &Model{ID: bson.ObjectIdHex("000000000000000000000001"), Source: "rc", Enabled: true, NextRunAt: now}
+
+	collection := &recordingCollection{due: []*Model{due}}
+	stubCollection(t, collection)
+
+	service := &MgoService{}
+	got, err := service.AcquireDue("replica-1", now, time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireDue() error = %v", err)
+	}
+	if got.ID != due.ID || got.LeaseOwner != "replica-1" || !got.LeaseUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("AcquireDue() = %+v, want the due schedule leased to replica-1 for a minute", got)
+	}
+
+	if _, err := service.AcquireDue("replica-1", now, time.Minute); err != mgo.ErrNotFound {
+		t.Errorf("AcquireDue() without due schedules error = %v, want %v", err, mgo.ErrNotFound)
+	}
+
+	wantQuery := bson.M{
+		"enabled":   true,
+		"nextRunAt": bson.M{"$lte": now},
+		"$or": []bson.M{
+			{"leaseUntil": bson.M{"$exists": false}},
+			{"leaseUntil": bson.M{"$lt": now}},
+		},
+	}
+	wantChange := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"leaseOwner": "replica-1",
+			"leaseUntil": now.Add(time.Minute),
+		}},
+		ReturnNew: true,
+	}
+	for index := range collection.queries {
+		if !reflect.DeepEqual(collection.queries[index], wantQuery) {
+			t.Errorf("AcquireDue() query = %#v, want %#v", collection.queries[index], wantQuery)
+		}
+		if !reflect.DeepEqual(collection.changes[index], wantChange) {
+			t.Errorf("AcquireDue() change = %#v, want %#v", collection.changes[index], wantChange)
+		}
+	}
+}
+
+func TestMgoService_Complete(t *testing.T) {
+	ranAt := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	model := &Model{ID: bson.ObjectIdHex("000000000000000000000001"), Cron: "*/5 * * * *"}
+
+	tests := []struct {
+		name          string
+		result        string
+		runErr        error
+		wantLastError string
+	}{
+		{"succeeded", "run-1", nil, ""},
+		{"failed", "", errors.New("Source unavailable"), "Source unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := &recordingCollection{}
+			stubCollection(t, collection)
+
+			if err := (&MgoService{}).Complete(model, "replica-1", ranAt, tt.result, tt.runErr); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+
+			wantSelectors := []bson.M{{
+				"_id":        model.ID,
+				"leaseOwner": "replica-1",
+			}}
+			if !reflect.DeepEqual(collection.selectors, wantSelectors) {
+				t.Errorf("Complete() selectors = %#v, want %#v", collection.selectors, wantSelectors)
+			}
+
+			wantUpdates := []bson.M{{
+				"$set": bson.M{
+					"nextRunAt":  ranAt.Add(5 * time.Minute),
+					"lastRunAt":  ranAt,
+					"lastResult": tt.result,
+					"lastError":  tt.wantLastError,
+				},
+				"$unset": bson.M{
+					"leaseOwner": "",
+					"leaseUntil": "",
+				},
+			}}
+			if !reflect.DeepEqual(collection.updates, wantUpdates) {
+				t.Errorf("Complete() updates = %#v, want %#v", collection.updates, wantUpdates)
+			}
+		})
+	}
+}
+
+func TestMgoService_CompleteInvalidCron(t *testing.T) {
+	collection := &recordingCollection{}
+	stubCollection(t, collection)
+
+	model := &Model{ID: bson.ObjectIdHex("000000000000000000000001"), Cron: "every now and then"}
+	if err := (&MgoService{}).Complete(model, "replica-1", time.Now(), "run-1", nil); err == nil {
+		t.Errorf("Complete() accepted an invalid cron expression")
+	}
+	if len(collection.updates) != 0 {
+		t.Errorf("Complete() sent %v, want no update", collection.updates)
+	}
+}
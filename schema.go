@@ -2,7 +2,14 @@ package main
 
 import (
 	"github.com/dukfaar/goUtils/relay"
+	"github.com/dukfaar/itemBackend/deadletter"
+	"github.com/dukfaar/itemBackend/export"
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/importsource"
 	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/review"
+	"github.com/dukfaar/itemBackend/schedule"
+	"github.com/dukfaar/itemBackend/snapshot"
 )
 
 var Schema string = `
@@ -12,19 +19,73 @@ var Schema string = `
 		}
 
 		type Query {
-			items(first: Int, last: Int, before: String, after: String, name: String): ItemConnection!
-			item(id: ID!): Item!
+			items(first: Int, last: Int, before: String, after: String, name: String, locale: String): ItemConnection!
+			item(id: ID!, locale: String): Item!
 
-			findItem(name: String, namespaceId: ID): Item
+			findItem(name: String, namespaceId: ID, locale: String): Item
+
+			importReports(source: String, first: Int): [ImportReport!]!
+			importReport(id: ID!): ImportReport
+
+			itemMergePolicy: [FieldMergePolicy!]!
+			itemProvenance(id: ID!): [FieldProvenance!]!
+
+			deadLetters(topic: String, first: Int, after: String): [DeadLetter!]!
+			deadLetter(id: ID!): DeadLetter
+
+			importReviews(status: ImportReviewStatus, first: Int, after: String): [ImportReview!]!
+			importReview(id: ID!): ImportReview
+
+			importSchedules: [ImportSchedule!]!
+
+			importSources: [ImportSource!]!
+			importSource(id: ID!): ImportSource
+
+			snapshots: [Snapshot!]!
+
+			logLevel: String!
 		}
 
 		type Mutation {
-			createItem(name: String, namespaceId: ID): Item!
-			updateItem(id: ID!, name: String, namespaceId: ID): Item!
+			createItem(name: String, namespaceId: ID, description: String, gatheringLevel: Int, gatheringJobId: ID, gatheringEffort: Int, price: Int, priceHq: Int, unspoiledNode: Boolean, availableFromNpc: Boolean): Item!
+			updateItem(id: ID!, name: String, namespaceId: ID, description: String, gatheringLevel: Int, gatheringJobId: ID, gatheringEffort: Int, price: Int, priceHq: Int, unspoiledNode: Boolean, availableFromNpc: Boolean): Item!
 			deleteItem(id: ID!): ID
+			unlockItemFields(id: ID!, fields: [String!]!): Item!
+
+			rcItemImport(namespace: String, dryRun: Boolean): String!
+			xivdbItemImport(namespace: String, dryRun: Boolean): String!
+			fileItemImport(content: String!, format: String!, locale: String, namespace: String, dryRun: Boolean): String!
+
+			replayDeadLetter(id: ID!): ID
+			replayAllDeadLetters(topic: String!): Int!
+
+			acceptImportReview(id: ID!, itemId: ID): ImportReview!
+			rejectImportReview(id: ID!): ImportReview!
+			redirectImportReview(id: ID!, itemId: ID!): ImportReview!
+
+			createImportSchedule(source: String!, cron: String!, namespace: String, enabled: Boolean, dryRun: Boolean): ImportSchedule!
+			updateImportSchedule(id: ID!, cron: String, namespace: String, enabled: Boolean, dryRun: Boolean): ImportSchedule!
+			deleteImportSchedule(id: ID!): ID
+
+			createImportSource(input: ImportSourceInput!): ImportSource!
+			updateImportSource(id: ID!, input: ImportSourceInput!): ImportSource!
+			deleteImportSource(id: ID!): ID
+			runImportSource(id: ID!, namespace: String, dryRun: Boolean): String!
+
+			exportItems(format: String!, filter: ItemExportFilter): ItemExport!
+
+			createSnapshot(label: String!): Snapshot!
+			restoreSnapshot(id: ID!, mode: SnapshotRestoreMode!): SnapshotRestoreResult!
+			deleteSnapshot(id: ID!): ID
 
-			rcItemImport(): String!
-			xivdbItemImport(): String!
+			setLogLevel(level: String!): String!
 		}` +
 	relay.PageInfoGraphQLString +
-	item.GraphQLType
+	item.GraphQLType +
+	importreport.GraphQLType +
+	deadletter.GraphQLType +
+	review.GraphQLType +
+	schedule.GraphQLType +
+	importsource.GraphQLType +
+	export.GraphQLType +
+	snapshot.GraphQLType
@@ -3,30 +3,56 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"flag"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/dukfaar/goUtils/env"
 	"github.com/dukfaar/goUtils/eventbus"
 	dukGraphql "github.com/dukfaar/goUtils/graphql"
 	dukHttp "github.com/dukfaar/goUtils/http"
 	"github.com/dukfaar/goUtils/permission"
+	"github.com/dukfaar/itemBackend/deadletter"
+	"github.com/dukfaar/itemBackend/export"
+	"github.com/dukfaar/itemBackend/graphqlws"
+	"github.com/dukfaar/itemBackend/health"
+	"github.com/dukfaar/itemBackend/importreport"
+	"github.com/dukfaar/itemBackend/importsource"
 	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	"github.com/dukfaar/itemBackend/metrics"
+	"github.com/dukfaar/itemBackend/persistedquery"
+	"github.com/dukfaar/itemBackend/querylimit"
+	"github.com/dukfaar/itemBackend/reference"
+	"github.com/dukfaar/itemBackend/review"
+	"github.com/dukfaar/itemBackend/schedule"
+	"github.com/dukfaar/itemBackend/snapshot"
+	"github.com/dukfaar/itemBackend/tracing"
 
 	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
 
 	"github.com/gorilla/websocket"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	graphqlRelay "github.com/graph-gophers/graphql-go/relay"
 
+	nsq "github.com/nsqio/go-nsq"
+
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+func apiGatewayAddress() (string, string) {
+	return env.GetDefaultEnvVar("API_GATEWAY_HOST", "localhost") + ":" + env.GetDefaultEnvVar("API_GATEWAY_PORT", "8090"),
+		env.GetDefaultEnvVar("API_GATEWAY_PATH", "/graphql")
+}
+
 func createApiGatewayFetcher() dukGraphql.Fetcher {
-	url := env.GetDefaultEnvVar("API_GATEWAY_HOST", "localhost") + ":" + env.GetDefaultEnvVar("API_GATEWAY_PORT", "8090")
-	path := env.GetDefaultEnvVar("API_GATEWAY_PATH", "/graphql")
+	url, path := apiGatewayAddress()
 
 	apiGatewayFetcher, err := dukGraphql.NewHttpFetcher(url, path)
 
@@ -42,7 +68,66 @@ func createApiGatewayFetcher() dukGraphql.Fetcher {
 	return loginApiGatewayFetcher
 }
 
+// loadPermissions retries loading the permission data until it succeeds, the service reports unready until then
+func loadPermissions(logger *logging.Logger, load func() error, loaded *health.Flag) {
+	delay := time.Second
+	for {
+		err := load()
+		if err == nil {
+			loaded.Set()
+			logger.Info("Loaded permission data")
+			return
+		}
+
+		logger.Warn("Loading permission data failed", logging.FieldError, err, "retryIn", delay.Seconds())
+		time.Sleep(delay)
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+func schedulerOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "itembackend"
+	}
+
+	return hostname + "-" + bson.NewObjectId().Hex()
+}
+
 func main() {
+	importFile := flag.String("import-file", "", "path to a local item dump (json, jsonl, csv or saintcoinach csv) to import on startup")
+	importFormat := flag.String("import-format", "", "format of the import file, guessed from the file extension if empty")
+	importLocale := flag.String("import-locale", item.DefaultLocale, "locale of the names in a saintcoinach import file")
+	importNamespace := flag.String("import-namespace", defaultImportNamespace, "name or id of the namespace to import the file into")
+	flag.Parse()
+
+	logger := logging.Root()
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		level, err := logging.ParseLevel(value)
+		if err != nil {
+			logger.Warn("Ignoring LOG_LEVEL", logging.FieldError, err)
+		} else {
+			logger.SetLevel(level)
+		}
+	}
+
+	traceSampleRatio, err := strconv.ParseFloat(env.GetDefaultEnvVar("OTLP_SAMPLE_RATIO", "1"), 64)
+	if err != nil || traceSampleRatio < 0 || traceSampleRatio > 1 {
+		traceSampleRatio = 1
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "itembackend",
+		Endpoint:    os.Getenv("OTLP_ENDPOINT"),
+		Insecure:    env.GetDefaultEnvVar("OTLP_INSECURE", "false") == "true",
+		SampleRatio: traceSampleRatio,
+	})
+	if err != nil {
+		panic(err)
+	}
+
 	dbSession, err := mgo.Dial(env.GetDefaultEnvVar("DB_HOST", "localhost"))
 	if err != nil {
 		panic(err)
@@ -51,37 +136,183 @@ func main() {
 
 	db := dbSession.DB("item")
 
-	nsqEventbus := eventbus.NewNsqEventBus(env.GetDefaultEnvVar("NSQD_TCP_URL", "localhost:4150"), env.GetDefaultEnvVar("NSQLOOKUP_HTTP_URL", "localhost:4161"))
+	nsqEventbus := metrics.NewEventBus(eventbus.NewNsqEventBus(env.GetDefaultEnvVar("NSQD_TCP_URL", "localhost:4150"), env.GetDefaultEnvVar("NSQLOOKUP_HTTP_URL", "localhost:4161")))
 	permissionService := permission.NewService()
 
-	itemService := item.NewMgoService(db, nsqEventbus)
+	mergePolicy, err := item.ParseMergePolicy(os.Getenv("ITEM_MERGE_POLICY"))
+	if err != nil {
+		panic(err)
+	}
+
+	mgoItemService := item.NewMgoService(db, nsqEventbus, logger)
+	// without the unique indexes concurrent imports can create the same item twice, so the service does not start
+	err = mgoItemService.EnsureIndexes()
+	if err != nil {
+		logger.Error("Creating unique item indexes failed, duplicate items have to be merged first", logging.FieldError, err)
+		panic(err)
+	}
+	itemService := item.NewTracedService(item.NewInstrumentedService(mgoItemService))
+	importReportService := importreport.NewMgoService(db)
+	deadLetterService := deadletter.NewMgoService(db)
+	reviewService := review.NewMgoService(db)
+	scheduleService := schedule.NewMgoService(db)
+	importSourceService := importsource.NewMgoService(db)
+	snapshotService := snapshot.NewMgoService(db)
+	exportService := export.NewMgoService(db)
+	err = exportService.EnsureIndexes()
+	if err != nil {
+		logger.Error("Creating the expiry index of item exports failed", logging.FieldError, err)
+	}
+	persistedQueryService := persistedquery.NewMgoService(db)
+	err = persistedQueryService.EnsureIndexes()
+	if err != nil {
+		logger.Error("Creating the unique index of persisted queries failed", logging.FieldError, err)
+	}
 
 	loginApiGatewayFetcher := createApiGatewayFetcher()
 
+	referenceCacheTTL, err := time.ParseDuration(env.GetDefaultEnvVar("REFERENCE_CACHE_TTL", "10m"))
+	if err != nil {
+		referenceCacheTTL = 10 * time.Minute
+	}
+	referenceResolver := reference.NewResolver(loginApiGatewayFetcher, referenceCacheTTL, logger)
+
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, "db", db)
 	ctx = context.WithValue(ctx, "itemService", itemService)
+	ctx = context.WithValue(ctx, "importReportService", importReportService)
+	ctx = context.WithValue(ctx, "mergePolicy", mergePolicy)
+	ctx = context.WithValue(ctx, "deadLetterService", deadLetterService)
+	ctx = context.WithValue(ctx, "importReviewService", reviewService)
+	ctx = context.WithValue(ctx, "scheduleService", scheduleService)
+	ctx = context.WithValue(ctx, "importSourceService", importSourceService)
+	ctx = context.WithValue(ctx, "exportService", exportService)
+	ctx = context.WithValue(ctx, "snapshotService", snapshotService)
 	ctx = context.WithValue(ctx, "permissionService", permissionService)
 	ctx = context.WithValue(ctx, "eventbus", nsqEventbus)
 	ctx = context.WithValue(ctx, "apigatewayfetcher", loginApiGatewayFetcher)
+	ctx = context.WithValue(ctx, "referenceResolver", referenceResolver)
 
-	resolver := &Resolver{}
-	schema := graphql.MustParseSchema(Schema, resolver)
+	maxImportJobs, err := strconv.Atoi(env.GetDefaultEnvVar("IMPORT_MAX_JOBS", "2"))
+	if err != nil || maxImportJobs < 1 {
+		maxImportJobs = 2
+	}
 
-	http.Handle("/graphql", dukHttp.AddContext(ctx, dukHttp.Authenticate(&graphqlRelay.Handler{
-		Schema: schema,
-	})))
+	importRunner := NewImportRunner(ImportDependencies{
+		Bus:           nsqEventbus,
+		ReportService: importReportService,
+		References:    referenceResolver,
+		RCItemURL:     env.GetDefaultEnvVar("RC_ITEM_URL", defaultRCItemURL),
+		Logger:        logger,
+	}, maxImportJobs)
+
+	maxPageSize, err := strconv.Atoi(env.GetDefaultEnvVar("QUERY_MAX_PAGE_SIZE", "100"))
+	if err != nil || maxPageSize < 1 {
+		maxPageSize = 100
+	}
+
+	maxComplexity, err := strconv.Atoi(env.GetDefaultEnvVar("QUERY_MAX_COMPLEXITY", "5000"))
+	if err != nil || maxComplexity < 1 {
+		maxComplexity = 5000
+	}
 
-	http.Handle("/socket", dukHttp.AddContext(ctx, &dukGraphql.SocketHandler{
+	rateLimit, err := strconv.ParseFloat(env.GetDefaultEnvVar("RATE_LIMIT_PER_SECOND", "10"), 64)
+	if err != nil || rateLimit <= 0 {
+		rateLimit = 10
+	}
+
+	rateLimitBurst, err := strconv.Atoi(env.GetDefaultEnvVar("RATE_LIMIT_BURST", "50"))
+	if err != nil || rateLimitBurst < 1 {
+		rateLimitBurst = 50
+	}
+
+	maxMetricOperations, err := strconv.Atoi(env.GetDefaultEnvVar("GRAPHQL_METRICS_MAX_OPERATIONS", "200"))
+	if err != nil || maxMetricOperations < 0 {
+		maxMetricOperations = 200
+	}
+
+	resolver := &Resolver{Imports: importRunner, MaxPageSize: int32(maxPageSize)}
+	schema := graphql.MustParseSchema(Schema, resolver,
+		graphql.Tracer(metrics.NewGraphQLTracer(tracing.GraphQLTracer{}, maxMetricOperations)))
+
+	maskDeniedFields := env.GetDefaultEnvVar("FIELD_PERMISSION_MODE", item.FieldPermissionStrict) == item.FieldPermissionMask
+
+	var graphqlHandler http.Handler = &graphqlRelay.Handler{
 		Schema: schema,
-		Upgrader: websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
-		},
-	}))
+	}
+	if maskDeniedFields {
+		graphqlHandler = MaskDeniedFields(graphqlHandler)
+	}
+
+	// every item of a connection is counted, the connection itself also runs the count and paging queries
+	queryLimiter := querylimit.NewLimiter(rateLimit, rateLimitBurst)
+	queryCosts := querylimit.NewCostModel(schema, map[string]int{"ItemConnection": 3, "Item": 1}, maxPageSize)
+	graphqlHandler = &querylimit.Handler{
+		Next:          graphqlHandler,
+		Limiter:       queryLimiter,
+		Costs:         queryCosts,
+		MaxComplexity: maxComplexity,
+	}
+
+	persistedQueryCacheTTL, err := time.ParseDuration(env.GetDefaultEnvVar("PERSISTED_QUERY_CACHE_TTL", "1h"))
+	if err != nil {
+		persistedQueryCacheTTL = time.Hour
+	}
+	persistedQueries := persistedquery.NewRegistry(
+		persistedQueryService,
+		env.GetDefaultEnvVar("PERSISTED_QUERY_MODE", persistedquery.ModeAutomatic),
+		persistedQueryCacheTTL,
+	)
+	graphqlHandler = &persistedquery.Handler{
+		Next:     graphqlHandler,
+		Registry: persistedQueries,
+	}
+
+	http.Handle("/graphql", tracing.Handler(dukHttp.AddContext(ctx, dukHttp.Authenticate(RequestLogger(logger, AddAcceptLanguage(graphqlHandler))))))
+
+	http.Handle("/importReport", tracing.Handler(dukHttp.AddContext(ctx, dukHttp.Authenticate(RequestLogger(logger, &importreport.Handler{
+		Service: importReportService,
+	})))))
+
+	http.Handle("/export", tracing.Handler(dukHttp.AddContext(ctx, dukHttp.Authenticate(RequestLogger(logger, &export.Handler{
+		Service:     exportService,
+		ItemService: itemService,
+	})))))
+
+	socketAuthInterval, err := time.ParseDuration(env.GetDefaultEnvVar("SOCKET_AUTH_INTERVAL", "1m"))
+	if err != nil {
+		socketAuthInterval = time.Minute
+	}
+
+	// socket operations are charged and scored one by one, after their persisted query has been resolved
+	socketOperations := &querylimit.Handler{
+		Limiter:       queryLimiter,
+		Costs:         queryCosts,
+		MaxComplexity: maxComplexity,
+	}
+	socketUpgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     AllowedOrigins(splitOrigins(os.Getenv("SOCKET_ALLOWED_ORIGINS"))),
+	}
+
+	socketOperationsHandler := &graphqlws.Handler{
+		Upgrader: socketUpgrader,
+		Hooks:    []graphqlws.Hook{persistedQueries.ResolveOperation, socketOperations.Operation},
+		Next: AddAcceptLanguage(&dukGraphql.SocketHandler{
+			Schema:   schema,
+			Upgrader: socketUpgrader,
+		}),
+	}
+	var socketHandler http.Handler = socketOperationsHandler
+	if maskDeniedFields {
+		socketHandler = MaskDeniedSocketFields(socketOperationsHandler)
+	}
+
+	http.Handle("/socket", dukHttp.AddContext(ctx, AuthenticateSocket(socketAuthInterval, RequestLogger(logger, &querylimit.Handler{
+		Limiter: queryLimiter,
+		Next:    socketHandler,
+	}))))
 
 	serviceInfo := eventbus.ServiceInfo{
 		Name:                  "item",
@@ -116,18 +347,26 @@ func main() {
 		}},
 	}
 
-	result, err := loginApiGatewayFetcher.Fetch(dukGraphql.Request{
-		Query: permission.Query,
-	})
-	if err != nil {
-		panic(err)
-	}
-	queryResult := dukGraphql.Response{result}
-
-	permission.ParseQueryResponse(queryResult, permissionService)
-	permissionService.BuildAllUserPermissionData()
-
-	nsqEventbus.On("service.up", "item", func(msg []byte) error {
+	permissionsLoaded := &health.Flag{}
+	go func() {
+		loadPermissions(logger, func() error {
+			result, err := loginApiGatewayFetcher.Fetch(dukGraphql.Request{
+				Query: permission.Query,
+			})
+			if err != nil {
+				return err
+			}
+			queryResult := dukGraphql.Response{result}
+
+			permission.ParseQueryResponse(queryResult, permissionService)
+			permissionService.BuildAllUserPermissionData()
+			return nil
+		}, permissionsLoaded)
+
+		permission.AddAuthEventsHandlers(nsqEventbus, permissionService)
+	}()
+
+	nsqEventbus.On("service.up", "item", metrics.Consume("service.up", func(msg []byte) error {
 		newService := eventbus.ServiceInfo{}
 		json.Unmarshal(msg, &newService)
 
@@ -136,26 +375,143 @@ func main() {
 		}
 
 		return nil
-	})
+	}))
 
 	eventDBSession := dbSession.Clone()
 	eventDB := eventDBSession.DB("item")
 	defer eventDBSession.Close()
-	eventItemService := item.NewMgoService(eventDB, nsqEventbus)
+	eventItemService := item.NewTracedService(item.NewInstrumentedService(item.NewMgoService(eventDB, nsqEventbus, logger)))
+	eventImportReportService := importreport.NewMgoService(eventDB)
+	eventDeadLetterService := deadletter.NewMgoService(eventDB)
+	eventReviewService := review.NewMgoService(eventDB)
+
+	maxImportAttempts, err := strconv.Atoi(env.GetDefaultEnvVar("IMPORT_MAX_ATTEMPTS", "3"))
+	if err != nil || maxImportAttempts < 1 {
+		maxImportAttempts = 3
+	}
 
-	nsqEventbus.On("import.item.by.rcname", "item", CreateRCEventImporter(eventItemService, loginApiGatewayFetcher))
-	nsqEventbus.On("import.item.by.xivdbid", "item", CreateXivdbEventImporter(eventItemService))
+	importRequeueDelay, err := time.ParseDuration(env.GetDefaultEnvVar("IMPORT_REQUEUE_DELAY", "5s"))
+	if err != nil {
+		importRequeueDelay = 5 * time.Second
+	}
+
+	importHandlers := map[string]func(msg []byte) error{
+		"import.item.by.rcname":  CreateRCEventImporter(eventItemService, eventImportReportService, mergePolicy, referenceResolver, logger),
+		"import.item.by.xivdbid": CreateXivdbEventImporter(eventItemService, eventImportReportService, eventReviewService, mergePolicy, logger),
+	}
+	importConsumers := make([]*nsq.Consumer, 0, len(importHandlers))
+	for topic, handler := range importHandlers {
+		consumer, err := deadletter.NewConsumer(topic, "item", env.GetDefaultEnvVar("NSQLOOKUP_HTTP_URL", "localhost:4161"), importRequeueDelay, logger,
+			deadletter.WithDeadLetter(eventDeadLetterService, logger, topic, maxImportAttempts, metrics.Consume(topic, measureQueueLag(topic, handler))))
+		if err != nil {
+			logger.Error("Subscribing to import events failed", logging.FieldTopic, topic, logging.FieldError, err)
+			panic(err)
+		}
+		importConsumers = append(importConsumers, consumer)
+	}
 
 	nsqEventbus.Emit("service.up", serviceInfo)
 
-	permission.AddAuthEventsHandlers(nsqEventbus, permissionService)
+	if *importFile != "" {
+		fileLogger := logger.With("file", *importFile)
+		itemList, err := ReadItemDumpFile(*importFile, *importFormat, *importLocale)
+		if err != nil {
+			fileLogger.Error("Importing item dump failed", logging.FieldError, err)
+		} else {
+			reporting, err := importRunner.StartFile(context.Background(), itemList, importNamespace, false)
+			if err != nil {
+				fileLogger.Error("Importing item dump failed", logging.FieldError, err)
+			} else {
+				fileLogger.Info("Read item dump", "items", len(itemList), logging.FieldImportRunID, reporting.ImportReportID)
+			}
+		}
+	}
+
+	reference.AddNamespaceEventHandlers(nsqEventbus, referenceResolver)
+
+	scheduleDBSession := dbSession.Clone()
+	defer scheduleDBSession.Close()
+	scheduler := &schedule.Scheduler{
+		Service:      schedule.NewMgoService(scheduleDBSession.DB("item")),
+		Owner:        schedulerOwner(),
+		PollInterval: 30 * time.Second,
+		Lease:        10 * time.Minute,
+		Trigger:      triggerScheduledImport(importRunner),
+		Logger:       logger,
+	}
+	stopScheduler := make(chan struct{})
+	go scheduler.Run(stopScheduler)
 
 	http.Handle("/metrics", promhttp.Handler())
 
+	healthCheckTimeout, err := time.ParseDuration(env.GetDefaultEnvVar("HEALTH_CHECK_TIMEOUT", "2s"))
+	if err != nil {
+		healthCheckTimeout = 2 * time.Second
+	}
+
+	healthClient := &http.Client{}
+	readiness := health.NewChecker(healthCheckTimeout)
+	readiness.Add("mongo", health.MongoPing(dbSession))
+	readiness.Add("nsqd", health.HTTPPing(healthClient, "http://"+env.GetDefaultEnvVar("NSQD_HTTP_URL", "localhost:4151")+"/ping"))
+	readiness.Add("nsqlookupd", health.HTTPPing(healthClient, "http://"+env.GetDefaultEnvVar("NSQLOOKUP_HTTP_URL", "localhost:4161")+"/ping"))
+	readiness.Add("permissions", permissionsLoaded.Check("Permission data has not been loaded from the api gateway"))
+	apiGatewayHost, apiGatewayPath := apiGatewayAddress()
+	readiness.Add("apigateway", health.GraphQLPing(healthClient, "http://"+apiGatewayHost+apiGatewayPath))
+
+	http.Handle("/healthz", health.LiveHandler())
+	http.Handle("/readyz", readiness.ReadyHandler())
+
 	dukGraphql.EmitRegisterEvents("registerQuery", schema.Inspect().QueryType(), nsqEventbus)
 	dukGraphql.EmitRegisterEvents("registerMutation", schema.Inspect().MutationType(), nsqEventbus)
 	dukGraphql.EmitRegisterEvents("registerSubscription", schema.Inspect().SubscriptionType(), nsqEventbus)
 	dukGraphql.EmitRegisterTypeEvents("registerType", schema.Inspect().Types(), nsqEventbus)
 
-	log.Fatal(http.ListenAndServe(":"+env.GetDefaultEnvVar("PORT", "8080"), nil))
+	shutdownTimeout, err := time.ParseDuration(env.GetDefaultEnvVar("SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil {
+		shutdownTimeout = 30 * time.Second
+	}
+
+	shutdownDrainDelay, err := time.ParseDuration(env.GetDefaultEnvVar("SHUTDOWN_DRAIN_DELAY", "5s"))
+	if err != nil {
+		shutdownDrainDelay = 5 * time.Second
+	}
+
+	httpServer := &http.Server{Addr: ":" + env.GetDefaultEnvVar("PORT", "8080")}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error("Serving http failed", logging.FieldError, err)
+			os.Exit(1)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	// requests keep being served until the orchestrator noticed the service is unready
+	readiness.Shutdown()
+	logger.Info("Shutting down", "drainDelay", shutdownDrainDelay.Seconds())
+	time.Sleep(shutdownDrainDelay)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	close(stopScheduler)
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Shutting down the http server failed", logging.FieldError, err)
+	}
+	if err := importRunner.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Draining running imports failed", logging.FieldError, err)
+	}
+	for _, consumer := range importConsumers {
+		consumer.Stop()
+		select {
+		case <-consumer.StopChan:
+		case <-shutdownCtx.Done():
+			logger.Error("Stopping import event consumer failed", logging.FieldError, shutdownCtx.Err())
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Flushing traces failed", logging.FieldError, err)
+	}
 }
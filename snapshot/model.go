@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	ModeReplace = "REPLACE"
+	ModeMerge   = "MERGE"
+)
+
+type Model struct {
+	ID        bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	Label     string        `json:"label" bson:"label"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	ItemCount int32         `json:"itemCount" bson:"itemCount"`
+	Size      int32         `json:"size" bson:"size"`
+	Chunks    int32         `json:"chunks" bson:"chunks"`
+}
+
+// Chunk holds a gzipped run of items as newline separated JSON, so a snapshot is not bound by the document size limit
+type Chunk struct {
+	ID         bson.ObjectId `json:"_id,omitempty" bson:"_id,omitempty"`
+	SnapshotID bson.ObjectId `json:"snapshotId" bson:"snapshotId"`
+	Index      int32         `json:"index" bson:"index"`
+	Data       []byte        `json:"data" bson:"data"`
+}
+
+type RestoreResult struct {
+	Created   int32
+	Updated   int32
+	Unchanged int32
+	Deleted   int32
+	Failed    int32
+}
+
+var GraphQLType = `
+	type Snapshot {
+		_id: ID!
+		label: String!
+		createdAt: String!
+		itemCount: Int!
+		size: Int!
+	}
+
+	enum SnapshotRestoreMode {
+		REPLACE
+		MERGE
+	}
+
+	type SnapshotRestoreResult {
+		created: Int!
+		updated: Int!
+		unchanged: Int!
+		deleted: Int!
+		failed: Int!
+	}
+`
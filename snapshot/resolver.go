@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Resolver struct {
+	Model *Model
+}
+
+func (r *Resolver) ID() graphql.ID {
+	return graphql.ID(r.Model.ID.Hex())
+}
+
+func (r *Resolver) Label() string {
+	return r.Model.Label
+}
+
+func (r *Resolver) CreatedAt() string {
+	return r.Model.CreatedAt.Format(time.RFC3339)
+}
+
+func (r *Resolver) ItemCount() int32 {
+	return r.Model.ItemCount
+}
+
+func (r *Resolver) Size() int32 {
+	return r.Model.Size
+}
+
+type RestoreResultResolver struct {
+	Result *RestoreResult
+}
+
+func (r *RestoreResultResolver) Created() int32 {
+	return r.Result.Created
+}
+
+func (r *RestoreResultResolver) Updated() int32 {
+	return r.Result.Updated
+}
+
+func (r *RestoreResultResolver) Unchanged() int32 {
+	return r.Result.Unchanged
+}
+
+func (r *RestoreResultResolver) Deleted() int32 {
+	return r.Result.Deleted
+}
+
+func (r *RestoreResultResolver) Failed() int32 {
+	return r.Result.Failed
+}
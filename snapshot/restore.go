@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+func ValidateMode(mode string) error {
+	if mode != ModeReplace && mode != ModeMerge {
+		return fmt.Errorf("Unknown restore mode: %v", mode)
+	}
+	return nil
+}
+
+func sameProvenance(a map[string]item.FieldProvenance, b map[string]item.FieldProvenance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for field, provenance := range a {
+		other, ok := b[field]
+		if !ok || other.Source != provenance.Source || other.Locked != provenance.Locked || !other.UpdatedAt.Equal(provenance.UpdatedAt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func restoreItem(logger *logging.Logger, itemService item.Service, model *item.Model, result *RestoreResult) {
+	id := model.ID.Hex()
+
+	existing, err := itemService.FindByID(id)
+	switch {
+	case err == mgo.ErrNotFound:
+		_, err = itemService.CreateWithID(model)
+		if err == nil {
+			result.Created++
+		}
+	case err != nil:
+	case len(item.Diff(existing, model)) == 0 && sameProvenance(existing.Provenance, model.Provenance):
+		result.Unchanged++
+		return
+	default:
+		_, err = itemService.Update(id, model)
+		if err == nil {
+			result.Updated++
+		}
+	}
+
+	if err != nil {
+		logger.Error("Restoring item failed", logging.FieldItemID, id, logging.FieldError, err)
+		result.Failed++
+	}
+}
+
+// AffectedNamespaces lists every namespace a restore of the snapshot writes to: those of the snapshot items,
+// those the existing versions of these items are in and, for REPLACE, those of every item that may be deleted.
+func AffectedNamespaces(snapshotService Service, itemService item.Service, id string, mode string) ([]bson.ObjectId, error) {
+	if err := ValidateMode(mode); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[bson.ObjectId]bool)
+	result := make([]bson.ObjectId, 0)
+	add := func(model *item.Model) error {
+		if !seen[model.NamespaceID] {
+			seen[model.NamespaceID] = true
+			result = append(result, model.NamespaceID)
+		}
+		return nil
+	}
+
+	if mode == ModeReplace {
+		if err := itemService.Iterate(itemService.MakeBaseQuery(), add); err != nil {
+			return nil, err
+		}
+	}
+
+	err := snapshotService.IterateItems(id, func(model *item.Model) error {
+		add(model)
+		if mode == ModeReplace {
+			return nil
+		}
+
+		existing, err := itemService.FindByID(model.ID.Hex())
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return add(existing)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Restore writes the items of a snapshot back through the item.Service, so the usual item events are emitted.
+// MERGE creates and updates the items of the snapshot, REPLACE additionally deletes every item that is not part of it.
+// Deleting happens first, so names freed by deleted items can be taken by restored ones.
+func Restore(logger *logging.Logger, snapshotService Service, itemService item.Service, id string, mode string) (*RestoreResult, error) {
+	if err := ValidateMode(mode); err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{}
+
+	if mode == ModeReplace {
+		snapshotIDs := make(map[bson.ObjectId]bool)
+		err := snapshotService.IterateItems(id, func(model *item.Model) error {
+			snapshotIDs[model.ID] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		obsoleteIDs := make([]string, 0)
+		err = itemService.Iterate(itemService.MakeBaseQuery(), func(model *item.Model) error {
+			if !snapshotIDs[model.ID] {
+				obsoleteIDs = append(obsoleteIDs, model.ID.Hex())
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obsoleteID := range obsoleteIDs {
+			if _, err := itemService.DeleteByID(obsoleteID); err != nil {
+				logger.Error("Deleting item failed", logging.FieldItemID, obsoleteID, logging.FieldError, err)
+				result.Failed++
+				continue
+			}
+			result.Deleted++
+		}
+	}
+
+	err := snapshotService.IterateItems(id, func(model *item.Model) error {
+		restoreItem(logger, itemService, model, result)
+		return nil
+	})
+
+	return result, err
+}
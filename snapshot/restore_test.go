@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dukfaar/itemBackend/item"
+	"github.com/dukfaar/itemBackend/logging"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// fakeItemService keeps items in memory and records the calls that emit item events
+type fakeItemService struct {
+	item.Service
+	items   map[string]*item.Model
+	created []string
+	updated []string
+	deleted []string
+}
+
+func (s *fakeItemService) FindByID(id string) (*item.Model, error) {
+	model, ok := s.items[id]
+	if !ok {
+		return nil, mgo.ErrNotFound
+	}
+	return model.Clone(), nil
+}
+
+func (s *fakeItemService) CreateWithID(model *item.Model) (*item.Model, error) {
+	s.items[model.ID.Hex()] = model.Clone()
+	s.created = append(s.created, model.Name)
+	return model, nil
+}
+
+func (s *fakeItemService) Update(id string, input interface{}) (*item.Model, error) {
+	model := input.(*item.Model)
+	s.items[id] = model.Clone()
+	s.updated = append(s.updated, model.Name)
+	return model, nil
+}
+
+func (s *fakeItemService) DeleteByID(id string) (string, error) {
+	s.deleted = append(s.deleted, s.items[id].Name)
+	delete(s.items, id)
+	return id, nil
+}
+
+func (s *fakeItemService) MakeBaseQuery() bson.M {
+	return bson.M{}
+}
+
+func (s *fakeItemService) Iterate(query bson.M, handler func(*item.Model) error) error {
+	for _, model := range s.items {
+		if err := handler(model.Clone()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeSnapshotService serves the items of a single snapshot from an encoded chunk
+type fakeSnapshotService struct {
+	Service
+	chunk []byte
+}
+
+func (s *fakeSnapshotService) IterateItems(id string, handler func(*item.Model) error) error {
+	return decodeChunk(s.chunk, handler)
+}
+
+func newItem(id string, name string, price int32) *item.Model {
+	return &item.Model{
+		ID:          bson.ObjectIdHex(id),
+		Name:        name,
+		NamespaceID: bson.ObjectIdHex("10112233445566778899aabb"),
+		Price:       &price,
+	}
+}
+
+func newRestoreFixture(t *testing.T) (*fakeSnapshotService, *fakeItemService) {
+	chunk, err := encodeChunk([]*item.Model{
+		newItem("000000000000000000000001", "Copper Ore", 3),
+		newItem("000000000000000000000002", "Tin Ore", 4),
+		newItem("000000000000000000000003", "Iron Ore", 5),
+	})
+	if err != nil {
+		t.Fatalf("encodeChunk() error = %v", err)
+	}
+
+	items := &fakeItemService{items: map[string]*item.Model{
+		"000000000000000000000001": newItem("000000000000000000000001", "Copper Ore", 3),
+		"000000000000000000000002": newItem("000000000000000000000002", "Tin Ore", 40),
+		"000000000000000000000004": newItem("000000000000000000000004", "Silver Ore", 6),
+	}}
+
+	return &fakeSnapshotService{chunk: chunk}, items
+}
+
+func TestRestore_Merge(t *testing.T) {
+	snapshots, items := newRestoreFixture(t)
+
+	result, err := Restore(logging.New(ioutil.Discard, logging.LevelError), snapshots, items, "", ModeMerge)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	want := RestoreResult{Created: 1, Updated: 1, Unchanged: 1}
+	if *result != want {
+		t.Errorf("Restore() = %+v, want %+v", *result, want)
+	}
+	if len(items.items) != 4 || *items.items["000000000000000000000002"].Price != 4 {
+		t.Errorf("Restore() left %v items, Tin Ore price %v", len(items.items), *items.items["000000000000000000000002"].Price)
+	}
+}
+
+func TestRestore_Replace(t *testing.T) {
+	snapshots, items := newRestoreFixture(t)
+
+	result, err := Restore(logging.New(ioutil.Discard, logging.LevelError), snapshots, items, "", ModeReplace)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	want := RestoreResult{Created: 1, Updated: 1, Unchanged: 1, Deleted: 1}
+	if *result != want {
+		t.Errorf("Restore() = %+v, want %+v", *result, want)
+	}
+	if len(items.deleted) != 1 || items.deleted[0] != "Silver Ore" {
+		t.Errorf("Restore() deleted %v, want [Silver Ore]", items.deleted)
+	}
+	if len(items.created) != 1 || items.created[0] != "Iron Ore" {
+		t.Errorf("Restore() created %v, want [Iron Ore]", items.created)
+	}
+}
+
+func TestAffectedNamespaces(t *testing.T) {
+	moved := bson.ObjectIdHex("20112233445566778899aabb")
+	deleted := bson.ObjectIdHex("30112233445566778899aabb")
+
+	tests := []struct {
+		mode string
+		want []bson.ObjectId
+	}{
+		{ModeMerge, []bson.ObjectId{bson.ObjectIdHex("10112233445566778899aabb"), moved}},
+		{ModeReplace, []bson.ObjectId{bson.ObjectIdHex("10112233445566778899aabb"), moved, deleted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			snapshots, items := newRestoreFixture(t)
+			items.items["000000000000000000000002"].NamespaceID = moved
+			items.items["000000000000000000000004"].NamespaceID = deleted
+
+			got, err := AffectedNamespaces(snapshots, items, "", tt.mode)
+			if err != nil {
+				t.Fatalf("AffectedNamespaces() error = %v", err)
+			}
+
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AffectedNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"time"
+
+	"github.com/dukfaar/itemBackend/item"
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+const chunkSize = 1000
+
+type Service interface {
+	Create(label string, iterate func(handler func(*item.Model) error) error) (*Model, error)
+	List() ([]Model, error)
+	FindByID(string) (*Model, error)
+	DeleteByID(string) (string, error)
+	IterateItems(id string, handler func(*item.Model) error) error
+}
+
+type MgoService struct {
+	db               *mgo.Database
+	collection       *mgo.Collection
+	chunksCollection *mgo.Collection
+}
+
+func NewMgoService(db *mgo.Database) *MgoService {
+	return &MgoService{
+		db:               db,
+		collection:       db.C("snapshots"),
+		chunksCollection: db.C("snapshotChunks"),
+	}
+}
+
+func encodeChunk(items []*item.Model) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	encoder := json.NewEncoder(writer)
+
+	for _, model := range items {
+		if err := encoder.Encode(model); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func decodeChunk(data []byte, handler func(*item.Model) error) error {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	for decoder.More() {
+		var model item.Model
+		if err := decoder.Decode(&model); err != nil {
+			return err
+		}
+		if err := handler(&model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Create writes the items handed over by iterate as gzipped chunks, the snapshot itself is only stored once all chunks are
+func (s *MgoService) Create(label string, iterate func(handler func(*item.Model) error) error) (*Model, error) {
+	model := &Model{
+		ID:        bson.NewObjectId(),
+		Label:     label,
+		CreatedAt: time.Now(),
+	}
+
+	pending := make([]*item.Model, 0, chunkSize)
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		data, err := encodeChunk(pending)
+		if err != nil {
+			return err
+		}
+
+		err = s.chunksCollection.Insert(&Chunk{
+			ID:         bson.NewObjectId(),
+			SnapshotID: model.ID,
+			Index:      model.Chunks,
+			Data:       data,
+		})
+		if err != nil {
+			return err
+		}
+
+		model.Chunks++
+		model.Size += int32(len(data))
+		pending = make([]*item.Model, 0, chunkSize)
+		return nil
+	}
+
+	err := iterate(func(itemModel *item.Model) error {
+		pending = append(pending, itemModel.Clone())
+		model.ItemCount++
+
+		if len(pending) >= chunkSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = s.collection.Insert(model)
+	}
+
+	if err != nil {
+		s.chunksCollection.RemoveAll(bson.M{"snapshotId": model.ID})
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (s *MgoService) List() ([]Model, error) {
+	var result []Model
+
+	err := s.collection.Find(bson.M{}).Sort("-createdAt").All(&result)
+
+	return result, err
+}
+
+func (s *MgoService) FindByID(id string) (*Model, error) {
+	var result Model
+
+	err := s.collection.FindId(bson.ObjectIdHex(id)).One(&result)
+
+	return &result, err
+}
+
+func (s *MgoService) DeleteByID(id string) (string, error) {
+	err := s.collection.RemoveId(bson.ObjectIdHex(id))
+	if err != nil {
+		return id, err
+	}
+
+	_, err = s.chunksCollection.RemoveAll(bson.M{"snapshotId": bson.ObjectIdHex(id)})
+
+	return id, err
+}
+
+func (s *MgoService) IterateItems(id string, handler func(*item.Model) error) error {
+	iter := s.chunksCollection.Find(bson.M{"snapshotId": bson.ObjectIdHex(id)}).Sort("index").Iter()
+
+	var chunk Chunk
+	for iter.Next(&chunk) {
+		if err := decodeChunk(chunk.Data, handler); err != nil {
+			iter.Close()
+			return err
+		}
+		chunk = Chunk{}
+	}
+
+	return iter.Close()
+}
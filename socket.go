@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	dukHttp "github.com/dukfaar/goUtils/http"
+)
+
+// AllowedOrigins checks the origin of socket upgrade requests against a list like "https://example.com".
+// "*" allows every origin, an empty list only the origin of the service itself.
+// Requests without an origin do not come from a browser and are always allowed.
+func AllowedOrigins(origins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		if len(origins) == 0 {
+			originURL, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(originURL.Host, r.Host)
+		}
+
+		for _, allowed := range origins {
+			if allowed == "*" || strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func splitOrigins(value string) []string {
+	result := make([]string, 0)
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			result = append(result, origin)
+		}
+	}
+	return result
+}
+
+// socketToken moves the access_token query parameter into the Authorization header,
+// browsers can not set headers on websocket connections
+func socketToken(r *http.Request) {
+	if r.Header.Get("Authorization") != "" {
+		return
+	}
+
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// authenticate only calls its next handler for an authenticated caller
+var authenticate = dukHttp.Authenticate
+
+func isAuthenticated(authenticate func(http.Handler) http.Handler, r *http.Request) bool {
+	authenticated := false
+	authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = true
+	})).ServeHTTP(&bufferedResponseWriter{header: http.Header{}}, r)
+	return authenticated
+}
+
+// watchedConn tells when the socket connection has been closed
+type watchedConn struct {
+	net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (c *watchedConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+// hijackRecorder hands the connection hijacked by the websocket upgrader to onHijack
+type hijackRecorder struct {
+	http.ResponseWriter
+	onHijack func(conn *watchedConn)
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Connection can not be hijacked")
+	}
+
+	conn, readWriter, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	watched := &watchedConn{Conn: conn, closed: make(chan struct{})}
+	w.onHijack(watched)
+	return watched, readWriter, nil
+}
+
+// AuthenticateSocket authenticates the upgrade request of a graphql socket, so socket operations run with the identity
+// the permission checks rely on. The token is validated again every interval and the connection is closed once it
+// has expired or been revoked.
+func AuthenticateSocket(interval time.Duration, next http.Handler) http.Handler {
+	// the revalidation of open sockets keeps the authenticator they were upgraded with
+	authenticate := authenticate
+	authenticated := authenticate(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		socketToken(r)
+
+		if !isAuthenticated(authenticate, r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		authenticated.ServeHTTP(&hijackRecorder{
+			ResponseWriter: w,
+			onHijack: func(conn *watchedConn) {
+				go revalidateSocket(authenticate, r, conn, interval)
+			},
+		}, r)
+	})
+}
+
+func revalidateSocket(authenticate func(http.Handler) http.Handler, r *http.Request, conn *watchedConn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.closed:
+			return
+		case <-ticker.C:
+			if !isAuthenticated(authenticate, r) {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
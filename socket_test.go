@@ -0,0 +1,172 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// tokenAuthenticator stands in for dukHttp.Authenticate, it only lets the current token pass
+type tokenAuthenticator struct {
+	mux   sync.Mutex
+	token string
+}
+
+func (a *tokenAuthenticator) revoke() {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.token = ""
+}
+
+func (a *tokenAuthenticator) valid(authorization string) bool {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.token != "" && authorization == "Bearer "+a.token
+}
+
+func stubAuthenticate(t *testing.T, token string) *tokenAuthenticator {
+	result := &tokenAuthenticator{token: token}
+
+	original := authenticate
+	authenticate = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !result.valid(r.Header.Get("Authorization")) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	t.Cleanup(func() { authenticate = original })
+
+	return result
+}
+
+func newSocketServer(t *testing.T, upgraded *bool) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(AuthenticateSocket(10*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*upgraded = true
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil || conn.WriteMessage(messageType, data) != nil {
+				return
+			}
+		}
+	})))
+}
+
+func socketURL(server *httptest.Server, token string) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/socket?access_token=" + token
+}
+
+func TestAuthenticateSocket_RejectsUpgrade(t *testing.T) {
+	stubAuthenticate(t, "valid")
+
+	upgraded := false
+	server := newSocketServer(t, &upgraded)
+	defer server.Close()
+
+	_, response, err := websocket.DefaultDialer.Dial(socketURL(server, "expired"), nil)
+	if err == nil {
+		t.Fatalf("Dial() upgraded an unauthenticated socket")
+	}
+	if response == nil || response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Dial() response = %+v, want %v", response, http.StatusUnauthorized)
+	}
+	if upgraded {
+		t.Errorf("AuthenticateSocket() passed an unauthenticated upgrade on")
+	}
+}
+
+func TestAuthenticateSocket_ClosesRevokedSocket(t *testing.T) {
+	authenticator := stubAuthenticate(t, "valid")
+
+	upgraded := false
+	server := newSocketServer(t, &upgraded)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(socketURL(server, "valid"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	// the socket stays open over several revalidations while the token is valid
+	time.Sleep(50 * time.Millisecond)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("ReadMessage() error = %v, want the socket kept open", err)
+	}
+
+	authenticator.revoke()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil || isTimeout(err) {
+		t.Errorf("ReadMessage() error = %v, want the socket closed once the token is revoked", err)
+	}
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins string
+		origin  string
+		want    bool
+	}{
+		{"no origin", "https://example.com", "", true},
+		{"same origin by default", "", "http://itembackend:8080", true},
+		{"other origin by default", "", "https://evil.example", false},
+		{"listed origin", "https://example.com/, https://admin.example.com", "https://admin.example.com", true},
+		{"unlisted origin", "https://example.com", "https://evil.example", false},
+		{"any origin", "*", "https://evil.example", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://itembackend:8080/socket", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := AllowedOrigins(splitOrigins(tt.origins))(r); got != tt.want {
+				t.Errorf("AllowedOrigins(%q)(%q) = %v, want %v", tt.origins, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSocketToken(t *testing.T) {
+	r := httptest.NewRequest("GET", "/socket?access_token=abc", nil)
+	socketToken(r)
+	if got := r.Header.Get("Authorization"); got != "Bearer abc" {
+		t.Errorf("socketToken() Authorization = %q", got)
+	}
+
+	r = httptest.NewRequest("GET", "/socket?access_token=abc", nil)
+	r.Header.Set("Authorization", "Bearer def")
+	socketToken(r)
+	if got := r.Header.Get("Authorization"); got != "Bearer def" {
+		t.Errorf("socketToken() replaced the Authorization header with %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	gqltrace "github.com/graph-gophers/graphql-go/trace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GraphQLTracer opens a span for every operation and for every resolver that is not trivial,
+// so the item service calls of a resolver show up beneath it
+type GraphQLTracer struct{}
+
+func (GraphQLTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, gqltrace.TraceQueryFinishFunc) {
+	name := "graphql"
+	if operationName != "" {
+		name = "graphql " + operationName
+	}
+
+	ctx, span := Start(ctx, name, trace.WithAttributes(
+		attribute.String("graphql.operation.name", operationName),
+		attribute.String("graphql.document", queryString),
+	))
+
+	return ctx, func(errs []*errors.QueryError) {
+		for _, err := range errs {
+			span.RecordError(err)
+		}
+		if len(errs) > 0 {
+			span.SetStatus(codes.Error, errs[0].Message)
+		}
+		span.End()
+	}
+}
+
+func (GraphQLTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, gqltrace.TraceFieldFinishFunc) {
+	if trivial {
+		return ctx, func(*errors.QueryError) {}
+	}
+
+	ctx, span := Start(ctx, typeName+"."+fieldName, trace.WithAttributes(
+		attribute.String("graphql.field.type", typeName),
+		attribute.String("graphql.field.name", fieldName),
+		attribute.String("graphql.field.path", label),
+	))
+
+	return ctx, func(err *errors.QueryError) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Message)
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dukfaar/goUtils/eventbus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/dukfaar/itemBackend"
+
+// PayloadField is the field of an event payload carrying the trace context of its emitter
+const PayloadField = "traceContext"
+
+type Config struct {
+	ServiceName string
+	// Endpoint is the host and port of the OTLP/HTTP collector, tracing stays disabled without one
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// Setup exports the spans of the process to the collector of config. Without an endpoint no spans are recorded,
+// the trace context of incoming requests and events is still passed on. The returned func flushes the pending spans.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, options...)
+}
+
+// End finishes span, marking it as failed if err is set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject adds the trace context of ctx to payload. Payloads that are not encoded as json objects,
+// e.g. the id of a deleted item, are returned as they are, as are all payloads emitted outside of a trace.
+func Inject(ctx context.Context, payload interface{}) interface{} {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return payload
+	}
+
+	if data, ok := payload.(map[string]interface{}); ok {
+		result := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			result[key] = value
+		}
+		result[PayloadField] = map[string]string(carrier)
+		return result
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return payload
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(encoded, &fields) != nil || fields == nil {
+		return payload
+	}
+	if fields[PayloadField], err = json.Marshal(carrier); err != nil {
+		return payload
+	}
+	return fields
+}
+
+// Extract continues the trace whose context Inject added to msg
+func Extract(ctx context.Context, msg []byte) context.Context {
+	var data struct {
+		TraceContext map[string]string `json:"traceContext"`
+	}
+	json.Unmarshal(msg, &data)
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(data.TraceContext))
+}
+
+// Emit publishes payload on topic within a producer span, its consumers continue the trace of ctx
+func Emit(ctx context.Context, bus eventbus.EventBus, topic string, payload interface{}) error {
+	ctx, span := Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", topic)),
+	)
+
+	err := bus.Emit(topic, Inject(ctx, payload))
+	End(span, err)
+	return err
+}
+
+// Consume handles the events of topic within a consumer span continuing the trace of their emitter
+func Consume(topic string, handler func(ctx context.Context, msg []byte) error) func(msg []byte) error {
+	return func(msg []byte) error {
+		ctx, span := Start(Extract(context.Background(), msg), topic+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attribute.String("messaging.destination.name", topic)),
+		)
+
+		err := handler(ctx, msg)
+		End(span, err)
+		return err
+	}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryExporter records every span in process instead of exporting it
+func useInMemoryExporter() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return exporter
+}
+
+type eventData struct {
+	Name string `json:"name"`
+}
+
+func TestInject(t *testing.T) {
+	useInMemoryExporter()
+	ctx, span := Start(context.Background(), "emit")
+	defer span.End()
+
+	tests := []struct {
+		name      string
+		payload   interface{}
+		wantTrace bool
+	}{
+		{"map", map[string]interface{}{"name": "abc"}, true},
+		{"struct", eventData{Name: "abc"}, true},
+		{"string", "00112233445566778899aabb", false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(Inject(ctx, tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantTrace {
+				want, _ := json.Marshal(tt.payload)
+				if string(encoded) != string(want) {
+					t.Errorf("Inject() = %s, want %s", encoded, want)
+				}
+				return
+			}
+
+			var data eventData
+			json.Unmarshal(encoded, &data)
+			if data.Name != "abc" {
+				t.Errorf("Inject() lost the payload, got %s", encoded)
+			}
+			got := trace.SpanContextFromContext(Extract(context.Background(), encoded))
+			if got.TraceID() != span.SpanContext().TraceID() {
+				t.Errorf("Extract() continued trace %v, want %v", got.TraceID(), span.SpanContext().TraceID())
+			}
+		})
+	}
+}
+
+func TestInject_WithoutTrace(t *testing.T) {
+	useInMemoryExporter()
+	payload := map[string]interface{}{"name": "abc"}
+
+	if got := Inject(context.Background(), payload); !reflect.DeepEqual(got, payload) {
+		t.Errorf("Inject() = %v, want the payload as it is", got)
+	}
+}
+
+func TestConsume(t *testing.T) {
+	exporter := useInMemoryExporter()
+	ctx, emitSpan := Start(context.Background(), "import")
+	msg, _ := json.Marshal(Inject(ctx, map[string]interface{}{"name": "abc"}))
+	emitSpan.End()
+
+	handler := Consume("import.item.by.rcname", func(ctx context.Context, msg []byte) error {
+		return errors.New("broken")
+	})
+	if err := handler(msg); err == nil || err.Error() != "broken" {
+		t.Fatalf("Consume() error = %v, want the error of the handler", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Consume() recorded %v spans, want 2", len(spans))
+	}
+	consumed := spans[1]
+	if consumed.Name != "import.item.by.rcname process" {
+		t.Errorf("Consume() span name = %v", consumed.Name)
+	}
+	if consumed.Parent.SpanID() != emitSpan.SpanContext().SpanID() {
+		t.Errorf("Consume() span parent = %v, want %v", consumed.Parent.SpanID(), emitSpan.SpanContext().SpanID())
+	}
+	if consumed.Status.Code != codes.Error {
+		t.Errorf("Consume() span status = %v, want an error", consumed.Status.Code)
+	}
+}
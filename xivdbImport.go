@@ -1,19 +1,53 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+
+	"github.com/dukfaar/itemBackend/logging"
 )
 
-func FetchXivdbItemData(ID int32) ([]byte, error) {
+func getWithContext(ctx context.Context, url string) (*http.Response, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.DefaultClient.Do(request.WithContext(ctx))
+}
+
+func FetchXivdbItemList(ctx context.Context) ([]XivdbItemListResponse, error) {
+	logger := logging.FromContext(ctx)
+	itemListResponse, err := getWithContext(ctx, "https://api.xivdb.com/item?columns=id")
+
+	if err != nil {
+		logger.Error("Getting the xivdb item list failed", logging.FieldError, err)
+		return nil, err
+	}
+	defer itemListResponse.Body.Close()
+
+	itemList := make([]XivdbItemListResponse, 0)
+	err = json.NewDecoder(itemListResponse.Body).Decode(&itemList)
+
+	if err != nil {
+		logger.Error("Reading the xivdb item list failed", logging.FieldError, err)
+		return nil, err
+	}
+
+	return itemList, nil
+}
+
+func FetchXivdbItemData(ctx context.Context, ID int32) ([]byte, error) {
 	idString := strconv.FormatInt(int64(ID), 10)
 
-	itemResponse, err := http.Get("https://api.xivdb.com/item/" + idString)
+	logger := logging.FromContext(ctx).With("xivdbId", ID)
+	itemResponse, err := getWithContext(ctx, "https://api.xivdb.com/item/"+idString)
 
 	if err != nil {
-		fmt.Errorf("Error getting item: %v", err)
+		logger.Error("Getting xivdb item failed", logging.FieldError, err)
 		return nil, err
 	}
 	defer itemResponse.Body.Close()
@@ -21,7 +55,7 @@ func FetchXivdbItemData(ID int32) ([]byte, error) {
 	result, err := ioutil.ReadAll(itemResponse.Body)
 
 	if err != nil {
-		fmt.Errorf("Error reading item: %v", err)
+		logger.Error("Reading xivdb item failed", logging.FieldError, err)
 		return nil, err
 	}
 